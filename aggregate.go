@@ -0,0 +1,367 @@
+package excel
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+)
+
+type AggregateFunc int
+
+const (
+	AggregateSum AggregateFunc = iota + 1
+	AggregateAverage
+	// AggregateCount count the non-blank cells
+	AggregateCount
+	AggregateMin
+	AggregateMax
+)
+
+/**
+formulaName return the excel function name of the aggregation
+*/
+func (f AggregateFunc) formulaName() string {
+	switch f {
+	case AggregateSum:
+		return "SUM"
+	case AggregateAverage:
+		return "AVERAGE"
+	case AggregateCount:
+		return "COUNTA"
+	case AggregateMin:
+		return "MIN"
+	case AggregateMax:
+		return "MAX"
+	default:
+		return ""
+	}
+}
+
+/**
+Aggregation aggregate the column of Path, the path is a tag path or the behind part of a tag path
+*/
+type Aggregation struct {
+	Path string
+	Func AggregateFunc
+}
+
+func Sum(path string) Aggregation {
+	return Aggregation{Path: path, Func: AggregateSum}
+}
+
+func Average(path string) Aggregation {
+	return Aggregation{Path: path, Func: AggregateAverage}
+}
+
+func Count(path string) Aggregation {
+	return Aggregation{Path: path, Func: AggregateCount}
+}
+
+func Min(path string) Aggregation {
+	return Aggregation{Path: path, Func: AggregateMin}
+}
+
+func Max(path string) Aggregation {
+	return Aggregation{Path: path, Func: AggregateMax}
+}
+
+type footer struct {
+	title        string
+	aggregations []Aggregation
+}
+
+type groupBy struct {
+	keys         []string
+	aggregations []Aggregation
+	mergeKeys    bool
+}
+
+/**
+isGrouped return whether the rows are grouped when exporting
+*/
+func (e *Excel) isGrouped() bool {
+	return e.groupBy != nil && len(e.groupBy.keys) != 0
+}
+
+/**
+subtotalNumber return the function number of the aggregation for the SUBTOTAL formula
+*/
+func (f AggregateFunc) subtotalNumber() int {
+	switch f {
+	case AggregateSum:
+		return 9
+	case AggregateAverage:
+		return 1
+	case AggregateCount:
+		return 3
+	case AggregateMin:
+		return 5
+	case AggregateMax:
+		return 4
+	default:
+		return 0
+	}
+}
+
+/**
+writeFooter write the footer row below the data rows of sheet, the data rows are from rowStart to footerRow-1
+*/
+func (e *Excel) writeFooter(sheet string, rows []interface{}, rowStart, footerRow int) (err error) {
+	if e.footer == nil || len(rows) == 0 {
+		return
+	}
+
+	t := reflect.Indirect(reflect.ValueOf(rows[0])).Type()
+	// the cols which have aggregations
+	aggregated := make(map[int]bool, len(e.footer.aggregations))
+	for _, aggregation := range e.footer.aggregations {
+		fieldIndex := fieldIndexByPath(t, aggregation.Path)
+		if fieldIndex < 0 {
+			err = errors.Errorf("path %s is not found in %s", aggregation.Path, t.Name())
+			return
+		}
+		aggregated[fieldCol(t, fieldIndex)] = true
+
+		// SUBTOTAL ignores the subtotal rows in the range
+		err = e.writeAggregation(sheet, aggregation.Func, rows, fieldIndex, rowStart, footerRow-1, footerRow, e.isGrouped())
+		if err != nil {
+			err = errors.Wrap(err, "e.writeAggregation")
+			return
+		}
+	}
+
+	if e.footer.title != "" && !aggregated[1] {
+		if err = e.setCellValue(sheet, 1, footerRow, e.footer.title); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+/**
+writeGroups write the rows grouped by the consecutive same values of the group keys from level, a subtotal
+row is written below every group, and the rows are outlined so that the groups can be collapsed.
+The row indices of the data rows are appended to rowIndices, the data rows are counted by p, and the next row index is returned.
+*/
+func (e *Excel) writeGroups(sheet string, rows []interface{}, level, rowStart int, rowIndices *[]int, p *phaseProgress) (nextRow int, err error) {
+	nextRow = rowStart
+	if len(e.groupBy.keys) > _maxOutlineLevel {
+		err = errors.Errorf("group keys are more than %d", _maxOutlineLevel)
+		return
+	}
+	if level == len(e.groupBy.keys) {
+		for _, row := range rows {
+			if err = e.writeRow(sheet, row, nextRow); err != nil {
+				err = errors.Wrap(err, "e.writeRow")
+				return
+			}
+			p.add(1)
+			if err = e.ex.SetRowOutlineLevel(sheet, nextRow, uint8(level)); err != nil {
+				err = errors.Wrap(err, "e.ex.SetRowOutlineLevel")
+				return
+			}
+			*rowIndices = append(*rowIndices, nextRow)
+			nextRow++
+		}
+		return
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	t := reflect.Indirect(reflect.ValueOf(rows[0])).Type()
+	keyIndex := fieldIndexByPath(t, e.groupBy.keys[level])
+	if keyIndex < 0 {
+		err = errors.Errorf("path %s is not found in %s", e.groupBy.keys[level], t.Name())
+		return
+	}
+	key := func(row interface{}) interface{} {
+		return exportValue(reflect.Indirect(reflect.ValueOf(row)).Field(keyIndex))
+	}
+
+	groupStart := 0
+	for i := 1; i <= len(rows); i++ {
+		if i < len(rows) && reflect.DeepEqual(key(rows[i]), key(rows[groupStart])) {
+			continue
+		}
+
+		group := rows[groupStart:i]
+		dataStart := nextRow
+		if nextRow, err = e.writeGroups(sheet, group, level+1, nextRow, rowIndices, p); err != nil {
+			return
+		}
+		if e.groupBy.mergeKeys && nextRow-dataStart > 1 {
+			if err = e.mergeCol(sheet, fieldCol(t, keyIndex), dataStart, nextRow-1); err != nil {
+				return
+			}
+		}
+
+		// subtotal row
+		for _, aggregation := range e.groupBy.aggregations {
+			fieldIndex := fieldIndexByPath(t, aggregation.Path)
+			if fieldIndex < 0 {
+				err = errors.Errorf("path %s is not found in %s", aggregation.Path, t.Name())
+				return
+			}
+			if err = e.writeAggregation(sheet, aggregation.Func, group, fieldIndex, dataStart, nextRow-1, nextRow, true); err != nil {
+				err = errors.Wrap(err, "e.writeAggregation")
+				return
+			}
+		}
+		if err = e.setCellValue(sheet, fieldCol(t, keyIndex), nextRow, fmt.Sprintf("%v %s", key(group[0]), _subtotalTitle)); err != nil {
+			return
+		}
+		if level > 0 {
+			if err = e.ex.SetRowOutlineLevel(sheet, nextRow, uint8(level)); err != nil {
+				err = errors.Wrap(err, "e.ex.SetRowOutlineLevel")
+				return
+			}
+		}
+		nextRow++
+
+		groupStart = i
+	}
+
+	return
+}
+
+func (e *Excel) setCellValue(sheet string, col, row int, value interface{}) (err error) {
+	axis, err := excelize.CoordinatesToCellName(col, row)
+	if err != nil {
+		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+		return
+	}
+	if err = e.ex.SetCellValue(sheet, axis, value); err != nil {
+		err = errors.Wrap(err, "e.ex.SetCellValue")
+		return
+	}
+	return
+}
+
+/**
+writeAggregation write the aggregation of the field in rows to the cell at row, the data rows are from
+rangeStart to rangeEnd, the SUBTOTAL formula is used if subtotal is true
+*/
+func (e *Excel) writeAggregation(sheet string, f AggregateFunc, rows []interface{}, fieldIndex, rangeStart, rangeEnd, row int, subtotal bool) (err error) {
+	col := fieldCol(reflect.Indirect(reflect.ValueOf(rows[0])).Type(), fieldIndex)
+	axis, err := excelize.CoordinatesToCellName(col, row)
+	if err != nil {
+		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+		return
+	}
+
+	if !e.footerFormula {
+		var values []interface{}
+		for _, r := range rows {
+			values = append(values, exportValue(reflect.Indirect(reflect.ValueOf(r)).Field(fieldIndex)))
+		}
+		if err = e.ex.SetCellValue(sheet, axis, aggregate(f, values)); err != nil {
+			err = errors.Wrap(err, "e.ex.SetCellValue")
+		}
+		return
+	}
+
+	var hCell, vCell string
+	if hCell, err = excelize.CoordinatesToCellName(col, rangeStart); err != nil {
+		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+		return
+	}
+	if vCell, err = excelize.CoordinatesToCellName(col, rangeEnd); err != nil {
+		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+		return
+	}
+	formula := fmt.Sprintf("%s(%s:%s)", f.formulaName(), hCell, vCell)
+	if subtotal {
+		formula = fmt.Sprintf("SUBTOTAL(%d,%s:%s)", f.subtotalNumber(), hCell, vCell)
+	}
+	if err = e.ex.SetCellFormula(sheet, axis, formula); err != nil {
+		err = errors.Wrap(err, "e.ex.SetCellFormula")
+	}
+	return
+}
+
+/**
+aggregate compute the aggregation of values, the blank and non-numeric values are ignored except by count
+*/
+func aggregate(f AggregateFunc, values []interface{}) interface{} {
+	var (
+		sum, min, max float64
+		count, number int
+	)
+	for _, value := range values {
+		if s := fmt.Sprint(value); value != nil && strings.TrimSpace(s) != "" {
+			count++
+		}
+
+		v, ok := toFloat(value)
+		if !ok {
+			continue
+		}
+		if number == 0 || v < min {
+			min = v
+		}
+		if number == 0 || v > max {
+			max = v
+		}
+		sum += v
+		number++
+	}
+
+	switch f {
+	case AggregateSum:
+		return sum
+	case AggregateAverage:
+		if number == 0 {
+			return 0
+		}
+		return sum / float64(number)
+	case AggregateCount:
+		return count
+	case AggregateMin:
+		return min
+	case AggregateMax:
+		return max
+	default:
+		return nil
+	}
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, !math.IsNaN(v)
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
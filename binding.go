@@ -0,0 +1,188 @@
+package excel
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+)
+
+var _fieldType = reflect.TypeOf((*Field)(nil)).Elem()
+
+/**
+binding is the compiled plan of a struct type for an importer, every tagged field is bound to the leaf
+node of its path or the column of its position tag, the fields whose path is not found are not bound
+*/
+type binding struct {
+	fields []boundField
+}
+
+type boundField struct {
+	// index of the struct field
+	index int
+	// leafNode is nil for a position tag
+	leafNode *Importer
+	// col is the col index of a position tag
+	col int
+}
+
+/**
+cellOf return the cell of the field in row
+*/
+func (f boundField) cellOf(row []string) string {
+	if f.leafNode != nil {
+		return f.leafNode.cellOf(row)
+	}
+	if f.col <= len(row) {
+		return row[f.col-1]
+	}
+	return ""
+}
+
+/**
+cellError return the CellError of the field
+*/
+func (f boundField) cellError(err error) *CellError {
+	if f.leafNode != nil {
+		return &CellError{colIndex: f.leafNode.colIndexStart, paths: f.leafNode.path, err: err}
+	}
+	return &CellError{colIndex: f.col, err: err}
+}
+
+/**
+isColTag report whether the tag is a position tag
+*/
+func isColTag(tag string) bool {
+	return strings.HasPrefix(tag, _tagColPrefix) || strings.HasPrefix(tag, _tagIndexPrefix)
+}
+
+/**
+parseColTag return the col index of a position tag, it begins with 1, ex: 3 for "col=C" or "#3"
+*/
+func parseColTag(tag string) (col int, err error) {
+	switch {
+	case strings.HasPrefix(tag, _tagColPrefix):
+		if col, err = excelize.ColumnNameToNumber(strings.TrimPrefix(tag, _tagColPrefix)); err != nil {
+			err = errors.Wrap(err, "excelize.ColumnNameToNumber")
+		}
+	case strings.HasPrefix(tag, _tagIndexPrefix):
+		if col, err = strconv.Atoi(strings.TrimPrefix(tag, _tagIndexPrefix)); err != nil || col < 1 {
+			err = errors.Errorf("position tag %s is invalid", tag)
+		}
+	default:
+		err = errors.Errorf("tag %s is not a position tag", tag)
+	}
+	return
+}
+
+/**
+Bind compile the plan from the header to the fields of t and cache it, t is a struct type or a pointer to
+struct type. ScanRow binds the types of the responses itself, call Bind to check the types before scanning.
+*/
+func (root *Importer) Bind(t reflect.Type) (err error) {
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	_, err = root.bind(t)
+	return
+}
+
+/**
+bind return the cached binding of the struct type t, it's compiled at the first time
+*/
+func (root *Importer) bind(t reflect.Type) (b *binding, err error) {
+	if root == nil {
+		return compileBinding(nil, t)
+	}
+
+	if cached, ok := root.bindings.Load(t); ok {
+		return cached.(*binding), nil
+	}
+	if b, err = compileBinding(root.getLeafNodes(), t); err != nil {
+		return
+	}
+	cached, _ := root.bindings.LoadOrStore(t, b)
+	return cached.(*binding), nil
+}
+
+/**
+compileBinding bind the tagged fields of t to leafNodes, the tag path can be the behind part of the leaf path
+*/
+func compileBinding(leafNodes []*Importer, t reflect.Type) (b *binding, err error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		err = errors.Errorf("type %v is not a struct", t)
+		return
+	}
+
+	b = new(binding)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(_tagFlag)
+		if tag == "" {
+			continue
+		}
+		if field.PkgPath != "" {
+			err = errors.Errorf("field %s.%s with tag %s is not exported", t.Name(), field.Name, tag)
+			return
+		}
+		if field.Type.Kind() == reflect.Interface || field.Type.Kind() == reflect.Ptr || !field.Type.Implements(_fieldType) {
+			err = errors.Errorf("field %s.%s is %s, not a Field", t.Name(), field.Name, field.Type)
+			return
+		}
+
+		if isColTag(tag) {
+			var col int
+			if col, err = parseColTag(tag); err != nil {
+				err = errors.Wrapf(err, "field %s.%s", t.Name(), field.Name)
+				return
+			}
+			b.fields = append(b.fields, boundField{index: i, col: col})
+			continue
+		}
+
+		path := strings.Split(tag, _tagPathSplitter)
+		for _, leafNode := range leafNodes {
+			if len(leafNode.path) >= len(path) && reflect.DeepEqual(leafNode.path[len(leafNode.path)-len(path):], path) {
+				b.fields = append(b.fields, boundField{index: i, leafNode: leafNode})
+				break
+			}
+		}
+	}
+	return
+}
+
+/**
+structOf return the struct which resp points to, resp can be a pointer to struct or a pointer to struct pointer
+*/
+func structOf(resp interface{}) (v reflect.Value, err error) {
+	v = reflect.ValueOf(resp)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = reflect.Indirect(v.Elem())
+		if v.Kind() == reflect.Struct {
+			return
+		}
+	}
+	err = errors.Errorf("response %T is not a struct pointer", resp)
+	return
+}
+
+/**
+Bind compile and cache the plans of the types for the headers of all the active sheets
+*/
+func (e *Excel) Bind(types ...reflect.Type) (err error) {
+	for i := range e.activeSheetNames {
+		var importer *Importer
+		if importer, err = e.importerAt(i); err != nil {
+			return
+		}
+		for _, t := range types {
+			if err = importer.Bind(t); err != nil {
+				err = errors.Wrapf(err, "sheet %s", importer.value)
+				return
+			}
+		}
+	}
+	return
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tangximing/excel"
+)
+
+/**
+runConvert convert the workbook by the extensions of the files, only the first active sheet is written to
+the formats which have no sheet, such as csv and json
+*/
+func runConvert(args []string, stdout, stderr io.Writer) (err error) {
+	fs, o := newFlagSet("convert", stderr)
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if fs.NArg() != 2 {
+		return errors.New("the input file and the output file are required")
+	}
+	in, out := fs.Arg(0), fs.Arg(1)
+
+	e, err := o.open(in)
+	if err != nil {
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(out))
+	if ext == ".xlsx" {
+		if err = e.GetFile().SaveAs(out); err != nil {
+			err = errors.Wrap(err, "SaveAs")
+		}
+		return
+	}
+
+	var write func(w io.Writer) error
+	switch ext {
+	case ".csv", ".tsv":
+		var delimiter rune
+		if delimiter, err = o.delimiterOf(out); err != nil {
+			return
+		}
+		// the delimiter of the output may be different from the input
+		excel.CSVDelimiter(delimiter)(e)
+		write = func(w io.Writer) error { return e.WriteCSV(w, o.sheet) }
+	case ".json":
+		write = func(w io.Writer) error { return e.WriteJSON(w, o.sheet) }
+	case ".ndjson", ".jsonl":
+		write = func(w io.Writer) error { return e.WriteNDJSON(w, o.sheet) }
+	case ".ods":
+		write = e.WriteODS
+	default:
+		return errors.Errorf("the format of %s is not supported", out)
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return errors.Wrap(err, "os.Create")
+	}
+	if err = write(f); err != nil {
+		_ = f.Close()
+		return errors.Wrapf(err, "write %s", out)
+	}
+	if err = f.Close(); err != nil {
+		err = errors.Wrap(err, "f.Close")
+	}
+	return
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+/**
+runGen generate a Go file with the struct for the header of the sheet, it can be used by go generate:
+
+	//go:generate excel gen -header-row 2 -pkg model -type Order -out order.go template.xlsx
+*/
+func runGen(args []string, stdout, stderr io.Writer) (err error) {
+	fs, o := newFlagSet("gen", stderr)
+	pkg := fs.String("pkg", "main", "the package name of the generated file")
+	typeName := fs.String("type", "Row", "the struct name")
+	out := fs.String("out", "", "the generated file, it's printed by default")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if fs.NArg() != 1 {
+		return errors.New("one template file is required")
+	}
+
+	e, err := o.open(fs.Arg(0))
+	if err != nil {
+		return
+	}
+	sheet := o.sheet
+	if sheet == "" {
+		sheets := e.GetActiveSheets()
+		if len(sheets) == 0 {
+			return errors.Errorf("no active sheet in %s", fs.Arg(0))
+		}
+		sheet = sheets[0]
+	}
+	code, err := e.GenerateStruct(sheet, *typeName)
+	if err != nil {
+		return errors.Wrap(err, "e.GenerateStruct")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by excel gen from %s. DO NOT EDIT.\n\n", filepath.Base(fs.Arg(0)))
+	fmt.Fprintf(&buf, "package %s\n\nimport \"github.com/tangximing/excel\"\n\n", *pkg)
+	buf.Write(code)
+
+	if *out == "" {
+		_, err = stdout.Write(buf.Bytes())
+		return
+	}
+	if err = ioutil.WriteFile(*out, buf.Bytes(), 0644); err != nil {
+		err = errors.Wrap(err, "ioutil.WriteFile")
+	}
+	return
+}
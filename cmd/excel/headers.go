@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+	"github.com/tangximing/excel"
+)
+
+/**
+runHeaders print the header tree of every active sheet as an indented outline, ex:
+
+	Sheet1
+	  字段 A1:E1
+	    字段1 A2
+*/
+func runHeaders(args []string, stdout, stderr io.Writer) (err error) {
+	fs, o := newFlagSet("headers", stderr)
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if fs.NArg() != 1 {
+		return errors.New("one file is required")
+	}
+
+	e, err := o.open(fs.Arg(0))
+	if err != nil {
+		return
+	}
+	for _, sheet := range e.GetActiveSheets() {
+		fmt.Fprintln(stdout, sheet)
+		var importer *excel.Importer
+		if importer, err = e.GetImporter(sheet); err != nil {
+			return errors.Wrap(err, "e.GetImporter")
+		}
+		if err = printImporter(stdout, importer, 1); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func printImporter(w io.Writer, node *excel.Importer, depth int) (err error) {
+	for _, child := range node.GetChildren() {
+		var ref string
+		if ref, err = cellRange(child); err != nil {
+			return
+		}
+		fmt.Fprintf(w, "%s%s %s\n", strings.Repeat("  ", depth), child.GetValue(), ref)
+		if err = printImporter(w, child, depth+1); err != nil {
+			return
+		}
+	}
+	return
+}
+
+/**
+cellRange return the range of the header cell, ex: A1:E1, or A2 for a single cell
+*/
+func cellRange(node *excel.Importer) (ref string, err error) {
+	colStart, colEnd := node.GetColIndexPos()
+	rowStart, rowEnd := node.GetRowIndexPos()
+	if ref, err = excelize.CoordinatesToCellName(colStart, rowStart); err != nil {
+		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+		return
+	}
+	if colStart == colEnd && rowStart == rowEnd {
+		return
+	}
+
+	end, err := excelize.CoordinatesToCellName(colEnd, rowEnd)
+	if err != nil {
+		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+		return
+	}
+	ref += ":" + end
+	return
+}
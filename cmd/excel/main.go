@@ -0,0 +1,193 @@
+/**
+Command excel inspects, validates and converts the workbooks with the header trees of the excel package.
+
+	excel headers  [flags] file
+	excel validate [flags] -spec spec.yaml file
+	excel convert  [flags] in out
+	excel split    [flags] -out dir file
+	excel merge    [flags] -out out.xlsx file...
+	excel gen      [flags] -type Name -pkg name file
+
+The xlsx, xls, ods, csv, json and ndjson files are supported by the extension.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tangximing/excel"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, stdout, stderr io.Writer) error
+}
+
+var _commands = []*command{
+	{"headers", "print the header tree of every sheet with the cell ranges", runHeaders},
+	{"validate", "check the header and the cells of a sheet against a spec", runValidate},
+	{"convert", "convert between xlsx, xls, ods, csv, json and ndjson", runConvert},
+	{"split", "write every sheet, or every n data rows of it, to a separate xlsx", runSplit},
+	{"merge", "put the sheets of several files to one xlsx, or append their data rows", runMerge},
+	{"gen", "generate the struct with the excel tags for the header of a template", runGen},
+}
+
+// errInvalid means the workbook is checked and it's invalid, the details are printed already
+var errInvalid = errors.New("invalid workbook")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	for _, cmd := range _commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		if err := cmd.run(args[1:], stdout, stderr); err != nil {
+			if err == flag.ErrHelp {
+				return 2
+			}
+			if err != errInvalid {
+				fmt.Fprintf(stderr, "excel %s: %v\n", cmd.name, err)
+			}
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "excel: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: excel <command> [flags] [files]")
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range _commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.usage)
+	}
+}
+
+/**
+openFlags is the flags to open a workbook which are shared by the commands
+*/
+type openFlags struct {
+	sheet       string
+	headerRow   int
+	headerRange string
+	delimiter   string
+	encoding    string
+}
+
+func newFlagSet(name string, stderr io.Writer) (*flag.FlagSet, *openFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	o := new(openFlags)
+	fs.StringVar(&o.sheet, "sheet", "", "the sheet to use, all the sheets by default")
+	fs.IntVar(&o.headerRow, "header-row", 0, "the last row of the header, the merged cells are the header by default")
+	fs.StringVar(&o.headerRange, "header-range", "", "the cell range of the header, ex: B3:H5")
+	fs.StringVar(&o.delimiter, "delimiter", "", "the delimiter of csv, a comma for csv and a tab for tsv by default")
+	fs.StringVar(&o.encoding, "encoding", "utf8", "the encoding of csv: utf8, utf8bom or gbk")
+	return fs, o
+}
+
+func (o *openFlags) options(file string) (options []excel.Option, err error) {
+	if o.sheet != "" {
+		options = append(options, excel.ActiveSheet(o.sheet))
+	}
+	if o.headerRow > 0 {
+		options = append(options, excel.HeaderRow(o.headerRow))
+	}
+	if o.headerRange != "" {
+		options = append(options, excel.HeaderRange(o.headerRange))
+	}
+
+	delimiter, err := o.delimiterOf(file)
+	if err != nil {
+		return
+	}
+	options = append(options, excel.CSVDelimiter(delimiter))
+
+	switch strings.ToLower(o.encoding) {
+	case "utf8", "utf-8":
+		options = append(options, excel.CSVEncoding(excel.EncodingUTF8))
+	case "utf8bom":
+		options = append(options, excel.CSVEncoding(excel.EncodingUTF8BOM))
+	case "gbk":
+		options = append(options, excel.CSVEncoding(excel.EncodingGBK))
+	default:
+		err = errors.Errorf("encoding %s is not supported", o.encoding)
+	}
+	return
+}
+
+/**
+delimiterOf return the delimiter of the csv file, it's the -delimiter flag if set, or a tab for tsv and a comma
+for the others
+*/
+func (o *openFlags) delimiterOf(file string) (delimiter rune, err error) {
+	if o.delimiter == "" {
+		if strings.EqualFold(filepath.Ext(file), ".tsv") {
+			return '\t', nil
+		}
+		return ',', nil
+	}
+
+	runes := []rune(strings.Replace(o.delimiter, `\t`, "\t", 1))
+	if len(runes) != 1 {
+		err = errors.Errorf("delimiter %q is not a character", o.delimiter)
+		return
+	}
+	return runes[0], nil
+}
+
+/**
+open open the workbook by the extension of file, the extra options are applied behind the flags
+*/
+func (o *openFlags) open(file string, extra ...excel.Option) (e *excel.Excel, err error) {
+	options, err := o.options(file)
+	if err != nil {
+		return
+	}
+	options = append(options, extra...)
+
+	var newExcel func(io.Reader, ...excel.Option) (*excel.Excel, error)
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".csv", ".tsv":
+		newExcel = excel.NewExcelFromCSV
+	case ".json":
+		newExcel = excel.NewExcelFromJSON
+	case ".ndjson", ".jsonl":
+		newExcel = excel.NewExcelFromNDJSON
+	default:
+		if e, err = excel.NewExcelFromFile(file, options...); err != nil {
+			err = errors.Wrap(err, "excel.NewExcelFromFile")
+		}
+		return
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		err = errors.Wrap(err, "os.Open")
+		return
+	}
+	defer f.Close()
+	if e, err = newExcel(f, options...); err != nil {
+		err = errors.Wrapf(err, "open %s", file)
+	}
+	return
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, 0, run([]string{"headers", "-header-row", "2", "../../test/test.xlsx"}, &stdout, &stderr))
+	assert.True(t, strings.HasPrefix(stdout.String(), "Sheet1\n  字段 A1:E1\n    字段1 A2\n"))
+
+	spec := filepath.Join(dir, "spec.json")
+	assert.Nil(t, ioutil.WriteFile(spec, []byte(`{"sheet": "Sheet1", "headerRow": 2, "columns": [
+		{"path": "字段|字段1"}, {"path": "字段|字段2", "type": "int"}, {"path": "字段|字段3", "type": "int"},
+		{"path": "字段|字段4", "type": "time"}, {"path": "字段|字段5", "type": "float"}]}`), 0644))
+	stdout.Reset()
+	assert.Equal(t, 1, run([]string{"validate", "-spec", spec, "../../test/test.xls"}, &stdout, &stderr))
+	assert.Equal(t, 3, strings.Count(stdout.String(), "表头：字段|字段3"))
+
+	out := filepath.Join(dir, "test.csv")
+	assert.Equal(t, 0, run([]string{"convert", "-header-row", "2", "-sheet", "Sheet2", "../../test/test.xlsx", out}, &stdout, &stderr))
+	stdout.Reset()
+	assert.Equal(t, 0, run([]string{"split", "-header-row", "2", "-rows", "2", "-out", dir, out}, &stdout, &stderr))
+	assert.Equal(t, 2, strings.Count(stdout.String(), ".xlsx"))
+
+	merged := filepath.Join(dir, "merged.xlsx")
+	parts := strings.Fields(stdout.String())
+	args := append([]string{"merge", "-header-row", "2", "-append", "-out", merged}, parts...)
+	assert.Equal(t, 0, run(args, &stdout, &stderr))
+	stdout.Reset()
+	assert.Equal(t, 0, run([]string{"convert", "-header-row", "2", merged, filepath.Join(dir, "merged.ndjson")}, &stdout, &stderr))
+	data, err := ioutil.ReadFile(filepath.Join(dir, "merged.ndjson"))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, strings.Count(string(data), "\n"))
+
+	// the duplicated sheet names are suffixed as the library does
+	copied := filepath.Join(dir, "copied.xlsx")
+	assert.Equal(t, 0, run([]string{"merge", "-sheet", "Sheet1", "-out", copied, "../../test/test.xlsx", "../../test/test.xlsx"}, &stdout, &stderr))
+	f, err := excelize.OpenFile(copied)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Sheet1", "Sheet1_2"}, f.GetSheetList())
+
+	stdout.Reset()
+	assert.Equal(t, 0, run([]string{"gen", "-header-row", "2", "-pkg", "model", "-type", "Test", "../../test/test.xlsx"}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "package model\n")
+	assert.Contains(t, stdout.String(), "type Test struct {\n")
+
+	// tsv is tab separated without -delimiter
+	tsv := filepath.Join(dir, "test.tsv")
+	assert.Equal(t, 0, run([]string{"convert", "-header-row", "2", "-sheet", "Sheet1", "../../test/test.xlsx", tsv}, &stdout, &stderr))
+	data, err = ioutil.ReadFile(tsv)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "字段\t\t\t\t\n字段1\t字段2\t"))
+	back := filepath.Join(dir, "back.csv")
+	assert.Equal(t, 0, run([]string{"convert", "-header-row", "2", tsv, back}, &stdout, &stderr))
+	data, err = ioutil.ReadFile(back)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "字段,,,,\n字段1,字段2,"))
+	stdout.Reset()
+	assert.Equal(t, 0, run([]string{"headers", "-header-row", "2", tsv}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "    字段5 E2\n")
+
+	assert.Equal(t, 2, run([]string{"unknown"}, &stdout, &stderr))
+}
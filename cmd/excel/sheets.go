@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+	"github.com/tangximing/excel"
+)
+
+/**
+runSplit write every active sheet to <out>/<sheet>.xlsx, or every n data rows of it to <out>/<sheet>_<i>.xlsx
+with the header rows copied
+*/
+func runSplit(args []string, stdout, stderr io.Writer) (err error) {
+	fs, o := newFlagSet("split", stderr)
+	out := fs.String("out", ".", "the directory of the split files")
+	n := fs.Int("rows", 0, "the max data rows of a split file, a sheet is not split by default")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if fs.NArg() != 1 {
+		return errors.New("one file is required")
+	}
+
+	e, err := o.open(fs.Arg(0))
+	if err != nil {
+		return
+	}
+	for _, sheet := range e.GetActiveSheets() {
+		if *n <= 0 {
+			file := filepath.Join(*out, sheet+".xlsx")
+			if err = saveSheets(file, []*sheetCopy{{name: sheet, from: e, sheet: sheet}}); err != nil {
+				return
+			}
+			fmt.Fprintln(stdout, file)
+			continue
+		}
+
+		var rows [][]string
+		if rows, err = e.GetSheetRowsWithoutHeader(sheet); err != nil {
+			return errors.Wrap(err, "e.GetSheetRowsWithoutHeader")
+		}
+		for i := 0; i*(*n) < len(rows); i++ {
+			end := (i + 1) * (*n)
+			if end > len(rows) {
+				end = len(rows)
+			}
+			file := filepath.Join(*out, fmt.Sprintf("%s_%d.xlsx", sheet, i+1))
+			c := &sheetCopy{name: sheet, from: e, sheet: sheet, headerOnly: true, rows: rows[i*(*n) : end]}
+			if err = saveSheets(file, []*sheetCopy{c}); err != nil {
+				return
+			}
+			fmt.Fprintln(stdout, file)
+		}
+	}
+	return
+}
+
+/**
+runMerge put every active sheet of the files to one xlsx, the duplicated sheet names are suffixed by a number
+as the library does, ex: Sheet1_2. The data rows are appended to the first sheet if -append is set, and the
+headers must be the same.
+*/
+func runMerge(args []string, stdout, stderr io.Writer) (err error) {
+	fs, o := newFlagSet("merge", stderr)
+	out := fs.String("out", "merged.xlsx", "the merged xlsx file")
+	appendRows := fs.Bool("append", false, "append the data rows to the first sheet")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if fs.NArg() == 0 {
+		return errors.New("no file to merge")
+	}
+
+	var (
+		copies []*sheetCopy
+		names  = make(map[string]bool)
+		// the leaf paths of the first sheet
+		header string
+	)
+	for _, file := range fs.Args() {
+		var e *excel.Excel
+		if e, err = o.open(file); err != nil {
+			return
+		}
+		for _, sheet := range e.GetActiveSheets() {
+			if !*appendRows {
+				copies = append(copies, &sheetCopy{name: excel.UniqueSheetName(sheet, names), from: e, sheet: sheet})
+				continue
+			}
+
+			var importer *excel.Importer
+			if importer, err = e.GetImporter(sheet); err != nil {
+				return errors.Wrap(err, "e.GetImporter")
+			}
+			leafPaths := strings.Join(getLeafPaths(importer), ",")
+			if len(copies) == 0 {
+				header = leafPaths
+				copies = append(copies, &sheetCopy{name: sheet, from: e, sheet: sheet, headerOnly: true})
+			} else if leafPaths != header {
+				return errors.Errorf("the header of %s %s is not the same as the first sheet", file, sheet)
+			}
+
+			var rows [][]string
+			if rows, err = e.GetSheetRowsWithoutHeader(sheet); err != nil {
+				return errors.Wrap(err, "e.GetSheetRowsWithoutHeader")
+			}
+			copies[0].rows = append(copies[0].rows, rows...)
+		}
+	}
+
+	if err = saveSheets(*out, copies); err != nil {
+		return
+	}
+	fmt.Fprintln(stdout, *out)
+	return
+}
+
+/**
+sheetCopy is a sheet copied from a workbook, the header rows and rows are copied if headerOnly is set,
+otherwise the whole sheet is copied
+*/
+type sheetCopy struct {
+	name       string
+	from       *excel.Excel
+	sheet      string
+	headerOnly bool
+	rows       [][]string
+}
+
+func saveSheets(file string, copies []*sheetCopy) (err error) {
+	f := excelize.NewFile()
+	for i, c := range copies {
+		if i == 0 {
+			f.SetSheetName(f.GetSheetName(0), c.name)
+		} else {
+			f.NewSheet(c.name)
+		}
+		if err = c.copyTo(f); err != nil {
+			return errors.Wrapf(err, "copy sheet %s", c.sheet)
+		}
+	}
+
+	if err = f.SaveAs(file); err != nil {
+		err = errors.Wrap(err, "f.SaveAs")
+	}
+	return
+}
+
+func (c *sheetCopy) copyTo(f *excelize.File) (err error) {
+	src := c.from.GetFile()
+	rows, err := src.GetRows(c.sheet)
+	if err != nil {
+		return errors.Wrap(err, "GetRows")
+	}
+
+	// the header rows end at the last row of the header cells
+	lastRow := len(rows)
+	if c.headerOnly {
+		var importer *excel.Importer
+		if importer, err = c.from.GetImporter(c.sheet); err != nil {
+			return errors.Wrap(err, "GetImporter")
+		}
+		lastRow = getHeaderHeight(importer)
+		if lastRow > len(rows) {
+			lastRow = len(rows)
+		}
+		rows = append(rows[:lastRow:lastRow], c.rows...)
+	}
+	for i, row := range rows {
+		for j, value := range row {
+			if value == "" {
+				continue
+			}
+			var axis string
+			if axis, err = excelize.CoordinatesToCellName(j+1, i+1); err != nil {
+				return errors.Wrap(err, "excelize.CoordinatesToCellName")
+			}
+			if err = f.SetCellValue(c.name, axis, cellValue(value)); err != nil {
+				return errors.Wrap(err, "SetCellValue")
+			}
+		}
+	}
+
+	mergeCells, err := src.GetMergeCells(c.sheet)
+	if err != nil {
+		return errors.Wrap(err, "GetMergeCells")
+	}
+	for _, mergeCell := range mergeCells {
+		var endRow int
+		if _, endRow, err = excelize.CellNameToCoordinates(mergeCell.GetEndAxis()); err != nil {
+			return errors.Wrap(err, "excelize.CellNameToCoordinates")
+		}
+		if endRow > lastRow {
+			continue
+		}
+		if err = f.MergeCell(c.name, mergeCell.GetStartAxis(), mergeCell.GetEndAxis()); err != nil {
+			return errors.Wrap(err, "MergeCell")
+		}
+	}
+	return
+}
+
+/**
+cellValue return the number if value is a decimal number, so that it's not written as text
+*/
+func cellValue(value string) interface{} {
+	if v, err := strconv.ParseFloat(value, 64); err == nil && strconv.FormatFloat(v, 'f', -1, 64) == value {
+		return v
+	}
+	return value
+}
+
+func getHeaderHeight(node *excel.Importer) (height int) {
+	if node == nil {
+		return
+	}
+	for _, child := range node.GetChildren() {
+		if _, rowEnd := child.GetRowIndexPos(); rowEnd > height {
+			height = rowEnd
+		}
+		if h := getHeaderHeight(child); h > height {
+			height = h
+		}
+	}
+	return
+}
+
+func getLeafPaths(node *excel.Importer) (paths []string) {
+	if node == nil {
+		return
+	}
+	for _, child := range node.GetChildren() {
+		if len(child.GetChildren()) == 0 {
+			paths = append(paths, strings.Join(child.GetPath(), "|"))
+			continue
+		}
+		paths = append(paths, getLeafPaths(child)...)
+	}
+	return
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/tangximing/excel"
+)
+
+/**
+runValidate print the header report and the cell errors of the sheet by the spec, errInvalid is returned if any
+*/
+func runValidate(args []string, stdout, stderr io.Writer) (err error) {
+	fs, o := newFlagSet("validate", stderr)
+	specFile := fs.String("spec", "", "the spec file of the columns, YAML or JSON")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if fs.NArg() != 1 || *specFile == "" {
+		return errors.New("one file and the spec are required")
+	}
+
+	spec, err := excel.LoadSpecFile(*specFile)
+	if err != nil {
+		return errors.Wrap(err, "excel.LoadSpecFile")
+	}
+	if o.sheet == "" {
+		o.sheet = spec.Sheet
+	}
+
+	e, err := o.open(fs.Arg(0), excel.SpecHeader(spec))
+	if err != nil {
+		return
+	}
+	report, err := e.HeaderReport(spec.Response())
+	if err != nil {
+		return errors.Wrap(err, "e.HeaderReport")
+	}
+	fmt.Fprintln(stdout, report)
+
+	invalid := !report.IsConsistent()
+	for _, sheet := range e.GetActiveSheets() {
+		err = e.ScanSheetRecords(sheet, spec, func(rowIndex int, record excel.Record, err error) error {
+			if err == nil {
+				return nil
+			}
+			invalid = true
+
+			var cellErr *excel.CellError
+			if errors.As(err, &cellErr) && cellErr.Unwrap() != nil {
+				fmt.Fprintf(stdout, "%s %s：%v\n", sheet, cellErr, cellErr.Unwrap())
+			} else {
+				fmt.Fprintf(stdout, "%s 第%d行：%v\n", sheet, rowIndex, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return errors.Wrap(err, "e.ScanSheetRecords")
+		}
+	}
+
+	if invalid {
+		return errInvalid
+	}
+	return
+}
@@ -0,0 +1,268 @@
+package excel
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+)
+
+/**
+Columns builds the header and the cell values of an export without struct tags, the titles can be
+computed at runtime, ex:
+
+	NewColumns().
+		Group("订单", func(g *Columns) {
+			g.Col("编号", func(row interface{}) interface{} { return row.(*Order).No })
+			g.Col("金额", func(row interface{}) interface{} { return row.(*Order).Amount }).Format("#,##0.00")
+		}).
+		Col(month+"销量", func(row interface{}) interface{} { return row.(*Order).Sales })
+*/
+type Columns struct {
+	title string
+	// items are *Column or *Columns in order
+	items []interface{}
+}
+
+type Column struct {
+	title  string
+	value  func(row interface{}) interface{}
+	format string
+}
+
+func NewColumns() *Columns {
+	return new(Columns)
+}
+
+/**
+Col add a column whose cell values are returned by value
+*/
+func (c *Columns) Col(title string, value func(row interface{}) interface{}) *Column {
+	col := &Column{title: title, value: value}
+	c.items = append(c.items, col)
+	return col
+}
+
+/**
+Group add a merged header whose sub columns are added by fn
+*/
+func (c *Columns) Group(title string, fn func(g *Columns)) *Columns {
+	g := &Columns{title: title}
+	fn(g)
+	c.items = append(c.items, g)
+	return c
+}
+
+/**
+Format set the number format of the cells, ex: "#,##0.00", "yyyy-mm-dd"
+*/
+func (col *Column) Format(format string) *Column {
+	col.format = format
+	return col
+}
+
+/**
+header return the header tree the same as parseHeader
+*/
+func (c *Columns) header() *header {
+	root := &header{isDummy: true}
+	c.addHeaders(root)
+	return root
+}
+
+func (c *Columns) addHeaders(h *header) {
+	for _, item := range c.items {
+		switch item := item.(type) {
+		case *Column:
+			h.children = append(h.children, &header{parent: h.title, title: item.title})
+		case *Columns:
+			child := &header{parent: h.title, title: item.title}
+			item.addHeaders(child)
+			h.children = append(h.children, child)
+		}
+	}
+}
+
+/**
+leaves return the columns in the order of the cells
+*/
+func (c *Columns) leaves() (cols []*Column) {
+	for _, item := range c.items {
+		switch item := item.(type) {
+		case *Column:
+			cols = append(cols, item)
+		case *Columns:
+			cols = append(cols, item.leaves()...)
+		}
+	}
+	return
+}
+
+/**
+NewExcelFromColumns write the header of columns and the rows to a sheet by the stream writer, so the
+rows are not kept in memory by the sheet. The Field values are written as GetValue, the times without format are written as "2006-01-02".
+Note: the partitions, the footer and the groups are not applied, and the rows of the sheet can't be read by e, the read
+methods return an error, save it and open it again to read them
+*/
+func NewExcelFromColumns(columns *Columns, rows []interface{}, options ...Option) (e *Excel, err error) {
+	e = newExcel()
+	for _, option := range options {
+		option(e)
+	}
+
+	root := columns.header()
+	if len(root.children) == 0 {
+		err = errors.New("no column exist")
+		return
+	}
+	if e.headerRow == 0 && e.headerRange == "" && len(e.headerTemplates) == 0 {
+		e.headerRow = root.getHeight() - 1
+	}
+
+	ex := excelize.NewFile()
+	sheet := fmt.Sprintf("%s%d", e.sheetPrefix, 1)
+	if sheet != _defaultSheetName {
+		ex.SetSheetName(_defaultSheetName, sheet)
+	}
+	e.ex = ex
+	// the sheet is initialized before streaming, reading it after streaming overwrites the streamed cells
+	if err = e.postInitialize(nil, nil); err != nil {
+		err = errors.Wrapf(err, "s.postInitialize")
+		return
+	}
+	headerRows, err := e.streamColumns(sheet, columns, root, rows)
+	if err != nil {
+		err = errors.Wrap(err, "e.streamColumns")
+		return
+	}
+	e.streamed = true
+
+	// the streamed sheet can't be read until it's saved, so the importer is built from the header rows the same as reading
+	area := headerArea{colStart: 1, colEnd: len(columns.leaves()), rowStart: 1, rowEnd: len(headerRows)}
+	mergeCells, err := e.headersOfRows(headerRows, area)
+	if err != nil {
+		err = errors.Wrap(err, "e.headersOfRows")
+		return
+	}
+	if e.importers[0], err = newImporter(sheet, area, mergeCells); err != nil {
+		err = errors.Wrap(err, "newImporter")
+		return
+	}
+
+	return
+}
+
+/**
+streamColumns write the header and the rows to sheet by the stream writer, the text of the header rows is returned
+*/
+func (e *Excel) streamColumns(sheet string, columns *Columns, root *header, rows []interface{}) (headers [][]string, err error) {
+	// the merged cells are added to the sheet before streaming, the stream writer writes them at last
+	height := root.getHeight() - 1
+	headerRows := make([][]interface{}, height)
+	for i := range headerRows {
+		headerRows[i] = make([]interface{}, 0)
+	}
+	if _, err = e.layoutHeader(sheet, root, 1, 0, headerRows); err != nil {
+		err = errors.Wrap(err, "e.layoutHeader")
+		return
+	}
+
+	cols := columns.leaves()
+	styleIds := make([]int, len(cols))
+	for i, col := range cols {
+		if col.format == "" {
+			continue
+		}
+		format := col.format
+		if styleIds[i], err = e.ex.NewStyle(&excelize.Style{CustomNumFmt: &format}); err != nil {
+			err = errors.Wrap(err, "e.ex.NewStyle")
+			return
+		}
+	}
+
+	sw, err := e.ex.NewStreamWriter(sheet)
+	if err != nil {
+		err = errors.Wrap(err, "e.ex.NewStreamWriter")
+		return
+	}
+	for i, headerRow := range headerRows {
+		if err = sw.SetRow(fmt.Sprintf("A%d", i+1), headerRow); err != nil {
+			err = errors.Wrap(err, "sw.SetRow")
+			return
+		}
+		header := make([]string, len(headerRow))
+		for j, cell := range headerRow {
+			if cell, ok := cell.(excelize.Cell); ok && cell.Value != nil {
+				header[j] = fmt.Sprint(cell.Value)
+			}
+		}
+		headers = append(headers, header)
+	}
+	for i, row := range rows {
+		values := make([]interface{}, len(cols))
+		for j, col := range cols {
+			value := col.value(row)
+			if field, ok := value.(Field); ok {
+				value = field.GetValue()
+			}
+			if t, ok := value.(time.Time); ok && col.format == "" {
+				value = t.Format(_dateLayout)
+			}
+			values[j] = excelize.Cell{StyleID: styleIds[j], Value: value}
+		}
+		if err = sw.SetRow(fmt.Sprintf("A%d", height+i+1), values); err != nil {
+			err = errors.Wrap(err, "sw.SetRow")
+			return
+		}
+	}
+	if err = sw.Flush(); err != nil {
+		err = errors.Wrap(err, "sw.Flush")
+	}
+	return
+}
+
+/**
+layoutHeader put the header cells to the rows and merge the group cells, the same layout as writeHeader
+*/
+func (e *Excel) layoutHeader(sheet string, h *header, col, row int, rows [][]interface{}) (span int, err error) {
+	for _, child := range h.children {
+		var childSpan int
+		if childSpan, err = e.layoutHeader(sheet, child, col+span, row+1, rows); err != nil {
+			return
+		}
+		span += childSpan
+	}
+	if len(h.children) == 0 {
+		span = 1
+	}
+	if h.isDummy {
+		return
+	}
+
+	cells := rows[row-1]
+	for len(cells) < col+span-1 {
+		cells = append(cells, nil)
+	}
+	cells[col-1] = excelize.Cell{StyleID: e.headerStyleId, Value: h.title}
+	for i := col; i < col+span-1; i++ {
+		cells[i] = excelize.Cell{StyleID: e.headerStyleId}
+	}
+	rows[row-1] = cells
+
+	if span > 1 {
+		var hCell, vCell string
+		if hCell, err = excelize.CoordinatesToCellName(col, row); err != nil {
+			err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+			return
+		}
+		if vCell, err = excelize.CoordinatesToCellName(col+span-1, row); err != nil {
+			err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+			return
+		}
+		if err = e.ex.MergeCell(sheet, hCell, vCell); err != nil {
+			err = errors.Wrap(err, "e.ex.MergeCell")
+		}
+	}
+	return
+}
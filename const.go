@@ -1,10 +1,29 @@
 package excel
 
+import "time"
+
 const (
 	_defaultSheetIndex  = 0
 	_defaultSheetPrefix = "Sheet"
+	_defaultSheetName   = "Sheet1"
 	_defaultColStart    = 1
 
+	_detectHeaderMaxRows = 100
+	// the data rows to guess the field types by
+	_generateSampleRows = 100
+
+	_maxSheetRows       = 1048576
+	_maxSheetNameLength = 31
+
+	// the default interval of the progress reports
+	_progressInterval = 200 * time.Millisecond
+
+	_subtotalTitle   = "小计"
+	_maxOutlineLevel = 7
+
 	_tagFlag         = "excel"
 	_tagPathSplitter = "|"
+	// the position tags bind a field to a column without header, ex: `excel:"col=C"` or `excel:"#3"`
+	_tagColPrefix   = "col="
+	_tagIndexPrefix = "#"
 )
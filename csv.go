@@ -0,0 +1,189 @@
+package excel
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+type Encoding int
+
+const (
+	EncodingUTF8 Encoding = iota
+	// EncodingUTF8BOM is UTF-8 with the byte order mark, excel needs it to open a UTF-8 CSV correctly
+	EncodingUTF8BOM
+	EncodingGBK
+)
+
+var _utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+type csvOptions struct {
+	delimiter  rune
+	encoding   Encoding
+	lazyQuotes bool
+	quoteAll   bool
+}
+
+/**
+NewExcelFromCSV read a CSV to a sheet, and the header is built the same as a xlsx file, the first row
+is the header by default, use HeaderRow for a multi-row header whose blank cells continue the header on the left
+*/
+func NewExcelFromCSV(reader io.Reader, options ...Option) (e *Excel, err error) {
+	e = newExcel()
+	for _, option := range options {
+		option(e)
+	}
+	if e.headerRow == 0 && e.headerRange == "" && len(e.headerTemplates) == 0 {
+		// there is no merged cell in CSV
+		e.headerRow = 1
+	}
+
+	p := e.progress.start(PhaseOpen, "", 0)
+	e.ex = excelize.NewFile()
+	sheet := fmt.Sprintf("%s%d", e.sheetPrefix, 1)
+	if sheet != _defaultSheetName {
+		e.ex.SetSheetName(_defaultSheetName, sheet)
+	}
+	if err = e.readCSV(reader, sheet); err != nil {
+		err = errors.Wrap(err, "e.readCSV")
+		return
+	}
+
+	if err = e.postInitialize(nil, nil); err != nil {
+		err = errors.Wrapf(err, "s.postInitialize")
+		return
+	}
+	p.finish()
+
+	return
+}
+
+/**
+readCSV write the records of CSV to the sheet as strings
+*/
+func (e *Excel) readCSV(reader io.Reader, sheet string) (err error) {
+	switch e.csv.encoding {
+	case EncodingGBK:
+		reader = transform.NewReader(reader, simplifiedchinese.GBK.NewDecoder())
+	default:
+		// skip the byte order mark
+		br := bufio.NewReader(reader)
+		if bom, _ := br.Peek(len(_utf8BOM)); bytes.Equal(bom, _utf8BOM) {
+			_, _ = br.Discard(len(_utf8BOM))
+		}
+		reader = br
+	}
+
+	r := csv.NewReader(reader)
+	r.Comma = e.csv.delimiter
+	r.LazyQuotes = e.csv.lazyQuotes
+	r.FieldsPerRecord = -1
+
+	for rowIndex := 1; ; rowIndex++ {
+		var record []string
+		record, err = r.Read()
+		if err == io.EOF {
+			err = nil
+			break
+		}
+		if err != nil {
+			err = errors.Wrap(err, "r.Read")
+			return
+		}
+
+		for i, cell := range record {
+			if cell == "" {
+				continue
+			}
+			var axis string
+			if axis, err = excelize.CoordinatesToCellName(i+1, rowIndex); err != nil {
+				err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+				return
+			}
+			if err = e.ex.SetCellStr(sheet, axis, cell); err != nil {
+				err = errors.Wrap(err, "e.ex.SetCellStr")
+				return
+			}
+		}
+	}
+
+	return
+}
+
+/**
+WriteCSV write the rows of sheet to w as CSV, the first active sheet is written if sheet is empty.
+The rows are padded to the same length so that the merged header cells become blank cells.
+*/
+func (e *Excel) WriteCSV(w io.Writer, sheet string) (err error) {
+	if sheet == "" {
+		sheet = e.activeSheetNames[_defaultSheetIndex]
+	}
+	if err = e.checkReadable(); err != nil {
+		return
+	}
+	rows, err := e.ex.GetRows(sheet)
+	if err != nil {
+		err = errors.Wrap(err, "e.ex.GetRows")
+		return
+	}
+
+	switch e.csv.encoding {
+	case EncodingGBK:
+		tw := transform.NewWriter(w, simplifiedchinese.GBK.NewEncoder())
+		defer func() {
+			if closeErr := tw.Close(); err == nil && closeErr != nil {
+				err = errors.Wrap(closeErr, "tw.Close")
+			}
+		}()
+		w = tw
+	case EncodingUTF8BOM:
+		if _, err = w.Write(_utf8BOM); err != nil {
+			err = errors.Wrap(err, "w.Write")
+			return
+		}
+	}
+
+	width := 0
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	for i := range rows {
+		for len(rows[i]) < width {
+			rows[i] = append(rows[i], "")
+		}
+	}
+
+	if !e.csv.quoteAll {
+		cw := csv.NewWriter(w)
+		cw.Comma = e.csv.delimiter
+		if err = cw.WriteAll(rows); err != nil {
+			err = errors.Wrap(err, "cw.WriteAll")
+		}
+		return
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, row := range rows {
+		for i, cell := range row {
+			if i > 0 {
+				_, _ = bw.WriteRune(e.csv.delimiter)
+			}
+			_, _ = bw.WriteString(`"` + strings.ReplaceAll(cell, `"`, `""`) + `"`)
+		}
+		_, _ = bw.WriteString("\n")
+	}
+	if err = bw.Flush(); err != nil {
+		err = errors.Wrap(err, "bw.Flush")
+	}
+	return
+}
@@ -3,6 +3,8 @@ package excel
 import (
 	"fmt"
 	"strings"
+
+	"github.com/pkg/errors"
 )
 
 type CellError struct {
@@ -15,3 +17,52 @@ func (e *CellError) Error() string {
 	return fmt.Sprintf("单元格填写错误。表头：%s, 行列：(%d, %d)",
 		strings.Join(e.paths, _tagPathSplitter), e.rowIndex, e.colIndex)
 }
+
+/**
+NewCellError return a CellError of the cell at the header path, path can be the behind part of the leaf path
+or a position tag, ex: "订单|编号", "编号" or "col=C". The cell is located when it's returned by the hooks of
+a response, or use RowContext.CellError in a middleware.
+*/
+func NewCellError(path string, err error) error {
+	if isColTag(path) {
+		colIndex, _ := parseColTag(path)
+		return &CellError{colIndex: colIndex, err: err}
+	}
+	return &CellError{paths: strings.Split(path, _tagPathSplitter), err: err}
+}
+
+/**
+RowIndex return the row index of the cell, it begins with 1, 0 means the row is unknown
+*/
+func (e *CellError) RowIndex() int {
+	return e.rowIndex
+}
+
+/**
+ColIndex return the col index of the cell, it begins with 1
+*/
+func (e *CellError) ColIndex() int {
+	return e.colIndex
+}
+
+/**
+Paths return the header path of the cell
+*/
+func (e *CellError) Paths() []string {
+	return e.paths
+}
+
+func (e *CellError) Unwrap() error {
+	return e.err
+}
+
+/**
+withRowIndex set the row index of the CellError in err
+*/
+func withRowIndex(err error, rowIndex int) error {
+	var cellErr *CellError
+	if errors.As(err, &cellErr) && cellErr.rowIndex == 0 {
+		cellErr.rowIndex = rowIndex
+	}
+	return err
+}
@@ -3,6 +3,11 @@ package excel
 import (
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 
 	"github.com/360EntSecGroup-Skylar/excelize/v2"
 	"github.com/pkg/errors"
@@ -14,22 +19,70 @@ type Excel struct {
 	password    string
 	sheetCount  int
 	sheetPrefix string
-	headerRow   int
 
+	// header
+	headerRow       int
+	headerRange     string
+	headerTemplates []interface{}
+	dataStartRow    int
+	noHeader        bool
+
+	// rules applied to the data rows
+	stopFilters []RowFilter
+	skipFilters []RowFilter
+
+	fillMergedCells bool
+	mergePaths      []string
+	evalFormulas    bool
+
+	// aggregations
+	footer  *footer
+	groupBy *groupBy
+
+	// sheets
+	partition     partition
+	sheetParts    []*sheetPart
+	footerFormula bool
+
+	// importers are the header trees of the active sheets in order, a tree is built when it's first accessed
 	importers           []*Importer
+	importersMu         sync.Mutex
 	activeSheetNames    []string
 	asyncScanWorkerNums int
 	humanErrorMsg       bool
+	middlewares         []Middleware
+	progress            *progressReporter
+	// streamed is true if the sheet is written by the stream writer, it can't be read until it's saved
+	streamed bool
+
+	csv csvOptions
 
 	// style
-	fieldStyleId int
+	fieldStyleId  int
+	headerStyle   *excelize.Style
+	headerStyleId int
 }
 
 func NewExcelFromFile(file string, options ...Option) (e *Excel, err error) {
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".xls", ".ods":
+		var f *os.File
+		if f, err = os.Open(file); err != nil {
+			err = errors.Wrap(err, "os.Open")
+			return
+		}
+		defer f.Close()
+		if ext == ".ods" {
+			return NewExcelFromODS(f, options...)
+		}
+		return NewExcelFromXLS(f, options...)
+	}
+
 	e = newExcel()
 	for _, option := range options {
 		option(e)
 	}
+	p := e.progress.start(PhaseOpen, "", 0)
 	if e.ex, err = excelize.OpenFile(file, excelize.Options{Password: e.password}); err != nil {
 		err = errors.Wrap(err, "excelize.OpenFile")
 		return
@@ -39,6 +92,7 @@ func NewExcelFromFile(file string, options ...Option) (e *Excel, err error) {
 		err = errors.Wrapf(err, "s.postInitialize")
 		return
 	}
+	p.finish()
 
 	return
 }
@@ -49,6 +103,7 @@ func NewExcelFromReader(reader io.Reader, options ...Option) (e *Excel, err erro
 		option(e)
 	}
 
+	p := e.progress.start(PhaseOpen, "", 0)
 	if e.ex, err = excelize.OpenReader(reader, excelize.Options{Password: e.password}); err != nil {
 		err = errors.Wrap(err, "excelize.OpenReader")
 		return
@@ -58,6 +113,7 @@ func NewExcelFromReader(reader io.Reader, options ...Option) (e *Excel, err erro
 		err = errors.Wrapf(err, "s.postInitialize")
 		return
 	}
+	p.finish()
 
 	return
 }
@@ -68,15 +124,23 @@ func NewExcelFromData(rows []interface{}, options ...Option) (e *Excel, err erro
 		option(e)
 	}
 
+	// the partitions are by the values after BeforeExport
+	beforeExport(rows)
 	e.ex = excelize.NewFile()
-	for i := 1; i <= e.sheetCount; i++ {
-		sheetName := fmt.Sprintf("%s%d", e.sheetPrefix, i)
-		e.ex.NewSheet(sheetName)
+	if e.sheetParts, err = e.partitionRows(rows); err != nil {
+		err = errors.Wrap(err, "e.partitionRows")
+		return
 	}
 
-	if e.sheetPrefix != _defaultSheetPrefix {
+	hasDefaultSheet := false
+	for _, part := range e.sheetParts {
+		e.ex.NewSheet(part.name)
+		hasDefaultSheet = hasDefaultSheet || part.name == _defaultSheetName
+	}
+
+	if !hasDefaultSheet {
 		// delete default sheet
-		e.ex.DeleteSheet("Sheet1")
+		e.ex.DeleteSheet(_defaultSheetName)
 	}
 
 	if err = e.postInitialize(rows, e.initFromData); err != nil {
@@ -91,6 +155,8 @@ func newExcel() (e *Excel) {
 	e = new(Excel)
 	e.sheetCount = 1
 	e.sheetPrefix = _defaultSheetPrefix
+	e.footerFormula = true
+	e.csv.delimiter = ','
 
 	return e
 }
@@ -119,17 +185,14 @@ func (e *Excel) postInitialize(rows []interface{}, initData initData) error {
 		return fmt.Errorf("init excel style error:(%+v)", err)
 	}
 
-	if len(rows) != 0 && initData != nil {
+	if initData != nil {
 		err := initData(rows)
 		if err != nil {
 			return errors.Wrap(err, "initData")
 		}
 	}
 
-	if err := e.initImporters(); err != nil {
-		return fmt.Errorf("init excel importers error:(%+v)", err)
-	}
-
+	e.importers = make([]*Importer, len(e.activeSheetNames))
 	return nil
 }
 
@@ -147,132 +210,411 @@ func (e *Excel) initStyle() (err error) {
 		return
 	}
 
+	e.headerStyleId = e.fieldStyleId
+	if e.headerStyle != nil {
+		if e.headerStyleId, err = e.ex.NewStyle(e.headerStyle); err != nil {
+			return
+		}
+	}
+
 	return
 }
 
-func (e *Excel) initImporters() (err error) {
-	for _, sheetName := range e.activeSheetNames {
-		root := new(Importer)
-		root.value = sheetName
-		root.colIndexStart = _defaultColStart
-		if root.colIndexEnd, err = e.getSheetLastColIndex(sheetName); err != nil {
+/**
+initImporter build the header tree of the sheet, only the header rows and the merged cells are read
+*/
+func (e *Excel) initImporter(sheetName string) (root *Importer, err error) {
+	if e.noHeader {
+		// the data rows begin at the first row, the fields are bound by the position tags
+		root = &Importer{value: sheetName}
+		return
+	}
+
+	var area headerArea
+	if area, err = e.getHeaderArea(sheetName); err != nil {
+		err = errors.Wrapf(err, "e.getHeaderArea")
+		return
+	}
+
+	// get sheet headers in merge cells format
+	var mergeCells []excelize.MergeCell
+	mergeCells, err = e.getHeaders(sheetName, area)
+	if err != nil {
+		err = errors.Wrapf(err, "e.getHeaders")
+		return
+	}
+
+	return newImporter(sheetName, area, mergeCells)
+}
+
+/**
+newImporter build the header tree of the sheet from the header cells in area
+*/
+func newImporter(sheetName string, area headerArea, mergeCells []excelize.MergeCell) (root *Importer, err error) {
+	root = new(Importer)
+	root.value = sheetName
+	root.colIndexStart, root.colIndexEnd = area.colStart, area.colEnd
+	// the children of root begin at the first header row
+	root.rowIndexStart, root.rowIndexEnd = area.rowStart-1, area.rowStart-1
+
+	if root.childImporters, err = buildChildNodes(root, mergeCells); err != nil {
+		return
+	}
+
+	return
+}
+
+/**
+importerAt return the header tree of the active sheet at index, it's built at the first time
+*/
+func (e *Excel) importerAt(index int) (importer *Importer, err error) {
+	e.importersMu.Lock()
+	defer e.importersMu.Unlock()
+
+	if index < 0 || index >= len(e.importers) {
+		err = errors.Errorf("active sheet %d doesn't exist", index)
+		return
+	}
+	if importer = e.importers[index]; importer != nil {
+		return
+	}
+	p := e.progress.start(PhaseHeader, e.activeSheetNames[index], 0)
+	if importer, err = e.initImporter(e.activeSheetNames[index]); err != nil {
+		err = errors.Wrapf(err, "init importer of sheet %s", e.activeSheetNames[index])
+		return
+	}
+	e.importers[index] = importer
+	p.finish()
+	return
+}
+
+/**
+headerArea is the cell range of the header, all the indices begin with 1
+*/
+type headerArea struct {
+	colStart, colEnd int
+	rowStart, rowEnd int
+}
+
+func (e *Excel) getHeaderArea(sheet string) (area headerArea, err error) {
+	switch {
+	case e.headerRange != "":
+		area, err = parseHeaderArea(e.headerRange)
+		if err != nil {
+			err = errors.Wrap(err, "parseHeaderArea")
+		}
+	case len(e.headerTemplates) != 0:
+		area, err = e.detectHeaderArea(sheet)
+		if err != nil {
+			err = errors.Wrap(err, "e.detectHeaderArea")
+		}
+	default:
+		area = headerArea{colStart: _defaultColStart, rowStart: 1, rowEnd: e.headerRow}
+		if area.colEnd, err = e.getSheetLastColIndex(sheet, e.headerRow); err != nil {
 			err = errors.Wrapf(err, "e.getSheetLastColIndex")
+		}
+	}
+
+	return
+}
+
+func parseHeaderArea(ref string) (area headerArea, err error) {
+	axes := strings.Split(ref, ":")
+	if len(axes) != 2 {
+		err = errors.Errorf("header range %s is invalid", ref)
+		return
+	}
+	if area.colStart, area.rowStart, err = excelize.CellNameToCoordinates(axes[0]); err != nil {
+		err = errors.Wrap(err, "excelize.CellNameToCoordinates")
+		return
+	}
+	if area.colEnd, area.rowEnd, err = excelize.CellNameToCoordinates(axes[1]); err != nil {
+		err = errors.Wrap(err, "excelize.CellNameToCoordinates")
+		return
+	}
+	if area.colStart > area.colEnd {
+		area.colStart, area.colEnd = area.colEnd, area.colStart
+	}
+	if area.rowStart > area.rowEnd {
+		area.rowStart, area.rowEnd = area.rowEnd, area.rowStart
+	}
+
+	return
+}
+
+/**
+detectHeaderArea find the last header row which all the leaf titles of the header templates end at, a leaf title
+can be above it if it's merged or continued downward to it, the header begins at the row which is as many rows above
+as the depth of the tag paths
+*/
+func (e *Excel) detectHeaderArea(sheet string) (area headerArea, err error) {
+	var (
+		titles = make(map[string]bool)
+		depth  int
+	)
+	for _, template := range e.headerTemplates {
+		t := reflect.TypeOf(template)
+		if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+			err = errors.New("header template is not struct ptr type")
 			return
 		}
 
-		// get sheet headers in merge cells format
-		var mergeCells []excelize.MergeCell
-		mergeCells, err = e.getHeaders(sheetName)
-		if err != nil {
-			err = errors.Wrapf(err, "e.getHeaders")
+		t = t.Elem()
+		for i := 0; i < t.NumField(); i++ {
+			tag := t.Field(i).Tag.Get(_tagFlag)
+			if tag == "" || isColTag(tag) {
+				continue
+			}
+			path := strings.Split(tag, _tagPathSplitter)
+			titles[path[len(path)-1]] = true
+			if len(path) > depth {
+				depth = len(path)
+			}
+		}
+	}
+
+	rows, err := e.ex.Rows(sheet)
+	if err != nil {
+		err = errors.Wrap(err, "e.ex.Rows")
+		return
+	}
+	var headerRows [][]string
+	for len(headerRows) < _detectHeaderMaxRows && rows.Next() {
+		var row []string
+		if row, err = rows.Columns(); err != nil {
+			err = errors.Wrap(err, "rows.Columns")
 			return
 		}
+		headerRows = append(headerRows, row)
+	}
 
-		if root.childImporters, err = buildChildNodes(root, mergeCells); err != nil {
+	mergeCells, err := e.ex.GetMergeCells(sheet)
+	if err != nil {
+		err = errors.Wrap(err, "e.ex.GetMergeCells")
+		return
+	}
+	// the end rows of the merged cells by their beginning cells
+	mergedRowEnds := make(map[[2]int]int, len(mergeCells))
+	for _, mergeCell := range mergeCells {
+		var col, row, rowEnd int
+		if col, row, err = excelize.CellNameToCoordinates(mergeCell.GetStartAxis()); err != nil {
+			err = errors.Wrap(err, "excelize.CellNameToCoordinates")
 			return
 		}
+		if _, rowEnd, err = excelize.CellNameToCoordinates(mergeCell.GetEndAxis()); err != nil {
+			err = errors.Wrap(err, "excelize.CellNameToCoordinates")
+			return
+		}
+		mergedRowEnds[[2]int{col, row}] = rowEnd
+	}
 
-		e.importers = append(e.importers, root)
+	// a leaf title can be above the last header row if it's merged or continued downward to the last header row
+	isLeaf := func(col, row, rowEnd int) bool {
+		if row == rowEnd || mergedRowEnds[[2]int{col, row}] >= rowEnd {
+			return true
+		}
+		for i := row + 1; i <= rowEnd; i++ {
+			if cells := headerRows[i-1]; col <= len(cells) && strings.TrimSpace(cells[col-1]) != "" {
+				return false
+			}
+		}
+		return true
 	}
 
+	for rowIndex := 1; rowIndex <= len(headerRows); rowIndex++ {
+		found, colStart, colEnd := make(map[string]bool), 0, 0
+		for i := rowIndex - depth + 1; i <= rowIndex; i++ {
+			if i < 1 {
+				continue
+			}
+			for j, col := range headerRows[i-1] {
+				title := strings.TrimSpace(col)
+				if !titles[title] || !isLeaf(j+1, i, rowIndex) {
+					continue
+				}
+				found[title] = true
+				if colStart == 0 || j+1 < colStart {
+					colStart = j + 1
+				}
+				if j+1 > colEnd {
+					colEnd = j + 1
+				}
+			}
+		}
+		if len(found) < len(titles) {
+			continue
+		}
+
+		area = headerArea{colStart: colStart, colEnd: colEnd, rowStart: rowIndex - depth + 1, rowEnd: rowIndex}
+		if area.rowStart < 1 {
+			err = errors.Errorf("header of sheet %s needs %d rows but found at row %d", sheet, depth, rowIndex)
+		}
+		return
+	}
+
+	err = errors.Errorf("header is not found in sheet %s", sheet)
 	return
 }
 
-func (e *Excel) getSheetLastColIndex(sheet string) (int, error) {
-	cols, err := e.ex.GetCols(sheet)
+/**
+getSheetLastColIndex return the last col index of the header, it's the longest header row or the last
+merged cell which begins in the header rows, the data rows are not read
+*/
+func (e *Excel) getSheetLastColIndex(sheet string, headerRowEnd int) (colEnd int, err error) {
+	headerRows, err := e.getHeaderRows(sheet, 1, headerRowEnd)
+	if err != nil {
+		err = errors.Wrap(err, "e.getHeaderRows")
+		return
+	}
+	for _, row := range headerRows {
+		for j := len(row); j > colEnd; j-- {
+			if strings.TrimSpace(row[j-1]) != "" {
+				colEnd = j
+				break
+			}
+		}
+	}
+
+	mergeCells, err := e.ex.GetMergeCells(sheet)
 	if err != nil {
-		return 0, err
+		err = errors.Wrap(err, "e.ex.GetMergeCells")
+		return
+	}
+	for _, mergeCell := range mergeCells {
+		var col, row int
+		if _, row, err = excelize.CellNameToCoordinates(mergeCell.GetStartAxis()); err != nil {
+			err = errors.Wrap(err, "excelize.CellNameToCoordinates")
+			return
+		}
+		if headerRowEnd != 0 && row > headerRowEnd {
+			continue
+		}
+		if col, _, err = excelize.CellNameToCoordinates(mergeCell.GetEndAxis()); err != nil {
+			err = errors.Wrap(err, "excelize.CellNameToCoordinates")
+			return
+		}
+		if col > colEnd {
+			colEnd = col
+		}
 	}
-	return len(cols), nil
+	return
 }
 
-func (e *Excel) getHeaders(sheet string) (headers []excelize.MergeCell, err error) {
-	if e.headerRow == 0 {
+func (e *Excel) getHeaders(sheet string, area headerArea) (headers []excelize.MergeCell, err error) {
+	if e.headerRow == 0 && e.headerRange == "" && len(e.headerTemplates) == 0 {
 		headers, err = e.ex.GetMergeCells(sheet)
 	} else {
-		headers, err = e.getHeadersFromRow(sheet)
+		headers, err = e.getHeadersFromRow(sheet, area)
 	}
 
 	return
 }
 
-func (e *Excel) getHeadersFromRow(sheet string) (headers []excelize.MergeCell, err error) {
-	headerRows, err := e.getHeaderRows(sheet)
+/**
+getHeadersFromRow build the header cells from the rows of the header area.
+A blank cell continues the header on its left, and a blank cell under a header
+which has no sub header continues the header downward.
+*/
+func (e *Excel) getHeadersFromRow(sheet string, area headerArea) (headers []excelize.MergeCell, err error) {
+	headerRows, err := e.getHeaderRows(sheet, area.rowStart, area.rowEnd)
 	if err != nil {
 		err = errors.Wrap(err, "e.getHeaderRows")
 		return
 	}
+	return e.headersOfRows(headerRows, area)
+}
+
+/**
+headersOfRows build the header cells from headerRows which are the rows of the header area, see getHeadersFromRow
+*/
+func (e *Excel) headersOfRows(headerRows [][]string, area headerArea) (headers []excelize.MergeCell, err error) {
 	if len(headerRows) == 0 {
 		return
 	}
 
+	type headerIndex struct {
+		header                             string
+		colStart, colEnd, rowStart, rowEnd int
+	}
+	var (
+		headerIndices []*headerIndex
+		// the col spans which the headers of next row are limited in
+		spans = []*headerIndex{{colStart: area.colStart, colEnd: area.colEnd}}
+	)
 	for i, row := range headerRows {
-		if len(row) == 0 || row[0] == "" {
-			continue
-		}
-
-		type headerIndex struct {
-			header     string
-			start, end int
-		}
-		headerIndices := make([]headerIndex, 0)
-		for j, col := range row {
-			if col == "" {
+		rowIndex := area.rowStart + i
+		nextSpans := make([]*headerIndex, 0, len(spans))
+		for _, span := range spans {
+			var children []*headerIndex
+			for j := span.colStart; j <= span.colEnd; j++ {
+				if j > len(row) || strings.TrimSpace(row[j-1]) == "" {
+					continue
+				}
+
+				if l := len(children); l > 0 {
+					children[l-1].colEnd = j - 1
+				}
+				children = append(children, &headerIndex{
+					header:   row[j-1],
+					colStart: j,
+					rowStart: rowIndex,
+					rowEnd:   rowIndex,
+				})
+			}
+			if len(children) == 0 {
+				if span.header != "" {
+					span.rowEnd = rowIndex
+				}
+				nextSpans = append(nextSpans, span)
 				continue
 			}
 
-			l := len(headerIndices)
-			if l > 0 {
-				headerIndices[l-1].end = j - 1
-			}
-			headerIndices = append(headerIndices, headerIndex{
-				header: col,
-				start:  j,
-			})
-		}
-		headerIndices[len(headerIndices)-1].end = len(row) - 1
-
-		for _, headerIndex := range headerIndices {
-			var header excelize.MergeCell
-			header, err = e.getMergeCell(headerIndex.start+1, headerIndex.end+1, i+1, headerIndex.header)
-			if err != nil {
-				err = errors.Wrap(err, "e.getMergeCell")
-				return
-			}
-			headers = append(headers, header)
+			children[len(children)-1].colEnd = span.colEnd
+			headerIndices = append(headerIndices, children...)
+			nextSpans = append(nextSpans, children...)
 		}
+		spans = nextSpans
+	}
 
+	for _, headerIndex := range headerIndices {
+		var header excelize.MergeCell
+		header, err = e.getMergeCell(headerIndex.colStart, headerIndex.rowStart, headerIndex.colEnd, headerIndex.rowEnd, headerIndex.header)
+		if err != nil {
+			err = errors.Wrap(err, "e.getMergeCell")
+			return
+		}
+		headers = append(headers, header)
 	}
 	return
 }
 
-func (e *Excel) getHeaderRows(sheet string) ([][]string, error) {
+func (e *Excel) getHeaderRows(sheet string, rowStart, rowEnd int) ([][]string, error) {
 	rows, err := e.ex.Rows(sheet)
 	if err != nil {
 		return nil, err
 	}
-	results := make([][]string, 0, 64)
+	results := make([][]string, 0, rowEnd-rowStart+1)
 
-	headerRow := e.headerRow
-	for rows.Next() && headerRow > 0 {
+	for rowIndex := 1; rowIndex <= rowEnd && rows.Next(); rowIndex++ {
 		row, err := rows.Columns()
 		if err != nil {
 			break
 		}
-		results = append(results, row)
-		headerRow--
+		if rowIndex >= rowStart {
+			results = append(results, row)
+		}
 	}
 	return results, nil
 }
 
-func (e *Excel) getMergeCell(startCol, endCol, row int, value string) (mergeCell excelize.MergeCell, err error) {
+func (e *Excel) getMergeCell(startCol, startRow, endCol, endRow int, value string) (mergeCell excelize.MergeCell, err error) {
 	var startAxis, endAxis string
-	startAxis, err = excelize.CoordinatesToCellName(startCol, row)
+	startAxis, err = excelize.CoordinatesToCellName(startCol, startRow)
 	if err != nil {
 		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
 		return
 	}
-	endAxis, err = excelize.CoordinatesToCellName(endCol, row)
+	endAxis, err = excelize.CoordinatesToCellName(endCol, endRow)
 	if err != nil {
 		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
 		return
@@ -288,8 +630,27 @@ func (e *Excel) GetFile() *excelize.File {
 	return e.ex
 }
 
+/**
+GetActiveSheets return the names of the active sheets, they are all the sheets if ActiveSheet is not set
+*/
+func (e *Excel) GetActiveSheets() []string {
+	return e.activeSheetNames
+}
+
+/**
+GetImporter return the header tree of the active sheet, an error is returned if the sheet is not active
+or its header is invalid
+*/
+func (e *Excel) GetImporter(sheet string) (*Importer, error) {
+	return e.importerOf(sheet)
+}
+
 func (e *Excel) IsHeaderConsistent(responses ...interface{}) (isConsistent bool, err error) {
-	for _, importer := range e.importers {
+	for i := range e.activeSheetNames {
+		var importer *Importer
+		if importer, err = e.importerAt(i); err != nil {
+			return
+		}
 		isConsistent, err = importer.IsHeaderConsistent(responses...)
 		if err != nil || !isConsistent {
 			return
@@ -300,14 +661,75 @@ func (e *Excel) IsHeaderConsistent(responses ...interface{}) (isConsistent bool,
 	return
 }
 
+func (e *Excel) HeaderReport(responses ...interface{}) (report *HeaderReport, err error) {
+	report = new(HeaderReport)
+	for i := range e.activeSheetNames {
+		var importer *Importer
+		if importer, err = e.importerAt(i); err != nil {
+			return
+		}
+		var sheetReport *SheetHeaderReport
+		if sheetReport, err = importer.HeaderReport(responses...); err != nil {
+			err = errors.Wrapf(err, "importer.HeaderReport")
+			return
+		}
+		report.Sheets = append(report.Sheets, sheetReport)
+	}
+
+	return
+}
+
 func (e *Excel) ScanRow(row []string, responses ...interface{}) (err error) {
-	importer := e.importers[_defaultSheetIndex]
-	return importer.ScanRow(row, responses...)
+	importer, err := e.importerAt(_defaultSheetIndex)
+	if err != nil {
+		return
+	}
+	return e.scanRow(importer, 0, row, responses)
 }
 
+/**
+AsyncScanRows scan rows to responses async, the rows are scanned as they are, the skip and stop rules are applied
+by the row getters such as GetRowsWithoutHeader
+*/
 func (e *Excel) AsyncScanRows(rows [][]string, responses ...interface{}) chan *AsyncScanExRes {
-	importer := e.importers[_defaultSheetIndex]
-	return importer.AsyncScanRows(rows, responses...)
+	importer, err := e.importerAt(_defaultSheetIndex)
+	if err != nil {
+		return asyncScanError(err)
+	}
+	p := e.progress.start(PhaseScan, importer.value, len(rows))
+	if len(rows) == 0 {
+		p.finish()
+	}
+	return importer.asyncScanRows(rows, func(row []string, responses ...interface{}) error {
+		defer p.add(1)
+		return e.scanRow(importer, 0, row, responses)
+	}, responses...)
+}
+
+/**
+ScanSheetRows scan the data rows of sheet one by one without loading the whole sheet, fn is called
+with the new receivers of each row and the scan error. Return an error in fn to stop scanning.
+*/
+func (e *Excel) ScanSheetRows(sheet string, fn func(rowIndex int, responses []interface{}, err error) error, responses ...interface{}) error {
+	importer, err := e.importerOf(sheet)
+	if err != nil {
+		return err
+	}
+	for _, resp := range responses {
+		v, err := structOf(resp)
+		if err != nil {
+			return err
+		}
+		if err = importer.Bind(v.Type()); err != nil {
+			return errors.Wrap(err, "importer.Bind")
+		}
+	}
+
+	return e.WalkSheetRows(sheet, func(rowIndex int, row []string) error {
+		respParams := newResponses(responses)
+		err := e.scanRow(importer, rowIndex, row, respParams)
+		return fn(rowIndex, respParams, withRowIndex(err, rowIndex))
+	})
 }
 
 func (e *Excel) GetRowsWithoutHeader() ([][]string, error) {
@@ -325,20 +747,183 @@ func (e *Excel) GetRowsWithoutHeader() ([][]string, error) {
 }
 
 func (e *Excel) GetSheetRowsWithoutHeader(sheet string) ([][]string, error) {
-	rowBeginIndex := e.importers[_defaultSheetIndex].getRowsBeginIndex()
-
 	var res [][]string
-	rows, err := e.ex.GetRows(sheet)
+	err := e.WalkSheetRows(sheet, func(_ int, row []string) error {
+		res = append(res, row)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	for i := range rows {
-		if i < rowBeginIndex {
+	return res, nil
+}
+
+/**
+WalkSheetRows walk the data rows of sheet one by one without loading the whole sheet,
+the skip and stop rules are applied, rowIndex begins with 1
+*/
+func (e *Excel) WalkSheetRows(sheet string, fn func(rowIndex int, row []string) error) error {
+	if err := e.checkReadable(); err != nil {
+		return err
+	}
+	importer, err := e.importerOf(sheet)
+	if err != nil {
+		return err
+	}
+	rowBeginIndex := e.getRowsBeginIndex(importer)
+
+	var mergedCells []*Importer
+	if e.fillMergedCells {
+		if mergedCells, err = e.getDataMergedCells(sheet, rowBeginIndex); err != nil {
+			return errors.Wrap(err, "e.getDataMergedCells")
+		}
+	}
+
+	rows, err := e.ex.Rows(sheet)
+	if err != nil {
+		return err
+	}
+
+	p := e.progress.start(PhaseScan, sheet, 0)
+	defer p.finish()
+	for rowIndex := 1; rows.Next(); rowIndex++ {
+		row, err := rows.Columns()
+		if err != nil {
+			return errors.Wrap(err, "rows.Columns")
+		}
+		if rowIndex <= rowBeginIndex {
 			continue
 		}
-		res = append(res, rows[i])
+		row = fillMergedCells(row, rowIndex, mergedCells)
+		if e.evalFormulas {
+			if row, err = e.evalRowFormulas(sheet, rowIndex, row); err != nil {
+				return errors.Wrap(err, "e.evalRowFormulas")
+			}
+		}
+
+		skip, stop := e.matchRowRules(row)
+		if stop {
+			break
+		}
+		if skip {
+			continue
+		}
+		if err = fn(rowIndex, row); err != nil {
+			return err
+		}
+		p.add(1)
 	}
 
-	return res, nil
+	return nil
+}
+
+/**
+checkReadable return an error if the rows of the sheets can't be read
+*/
+func (e *Excel) checkReadable() error {
+	if e.streamed {
+		return errors.New("the streamed sheet can't be read, save it and open it again to read")
+	}
+	return nil
+}
+
+/**
+getDataMergedCells return the merged cells below the header
+*/
+func (e *Excel) getDataMergedCells(sheet string, rowBeginIndex int) (cells []*Importer, err error) {
+	mergeCells, err := e.ex.GetMergeCells(sheet)
+	if err != nil {
+		err = errors.Wrap(err, "e.ex.GetMergeCells")
+		return
+	}
+
+	for _, mergeCell := range mergeCells {
+		var cell *Importer
+		if cell, err = buildNode(mergeCell); err != nil {
+			err = errors.Wrap(err, "buildNode")
+			return
+		}
+		if cell.rowIndexStart > rowBeginIndex {
+			cells = append(cells, cell)
+		}
+	}
+	return
+}
+
+/**
+fillMergedCells set the value of merged cells to every cell of row they cover
+*/
+func fillMergedCells(row []string, rowIndex int, mergedCells []*Importer) []string {
+	for _, cell := range mergedCells {
+		if rowIndex < cell.rowIndexStart || rowIndex > cell.rowIndexEnd {
+			continue
+		}
+		for len(row) < cell.colIndexEnd {
+			row = append(row, "")
+		}
+		for col := cell.colIndexStart; col <= cell.colIndexEnd; col++ {
+			row[col-1] = cell.value
+		}
+	}
+	return row
+}
+
+/**
+evalRowFormulas replace the values of formula cells with the calculated values
+*/
+func (e *Excel) evalRowFormulas(sheet string, rowIndex int, row []string) ([]string, error) {
+	for j := range row {
+		axis, err := excelize.CoordinatesToCellName(j+1, rowIndex)
+		if err != nil {
+			return nil, errors.Wrap(err, "excelize.CoordinatesToCellName")
+		}
+		formula, err := e.ex.GetCellFormula(sheet, axis)
+		if err != nil {
+			return nil, errors.Wrap(err, "e.ex.GetCellFormula")
+		}
+		if formula == "" {
+			continue
+		}
+		if row[j], err = e.ex.CalcCellValue(sheet, axis); err != nil {
+			return nil, errors.Wrapf(err, "e.ex.CalcCellValue %s", axis)
+		}
+	}
+	return row, nil
+}
+
+/**
+matchRowRules report whether the data row should be skipped, or the reading should stop at it
+*/
+func (e *Excel) matchRowRules(row []string) (skip, stop bool) {
+	for _, filter := range e.stopFilters {
+		if filter(row) {
+			return false, true
+		}
+	}
+	for _, filter := range e.skipFilters {
+		if filter(row) {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+func (e *Excel) importerOf(sheet string) (*Importer, error) {
+	for i, activeSheetName := range e.activeSheetNames {
+		if activeSheetName == sheet {
+			return e.importerAt(i)
+		}
+	}
+	return nil, errors.Errorf("sheet name %s is not active", sheet)
+}
+
+/**
+getRowsBeginIndex return the beginning row index of the data rows, it begins with 0
+*/
+func (e *Excel) getRowsBeginIndex(importer *Importer) int {
+	if e.dataStartRow > 0 {
+		return e.dataStartRow - 1
+	}
+	return importer.getRowsBeginIndex()
 }
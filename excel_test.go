@@ -1,11 +1,18 @@
 package excel
 
 import (
+	"archive/zip"
+	"bytes"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -78,3 +85,1276 @@ func TestExcel_AsyncScanRows(t *testing.T) {
 		fmt.Println(*t2)
 	}
 }
+
+func TestExcel_HeaderReport(t *testing.T) {
+	f, err := NewExcelFromFile("./test/test.xlsx", HeaderRow(2))
+	assert.Nil(t, err)
+
+	report, err := f.HeaderReport(new(test))
+	assert.Nil(t, err)
+	assert.True(t, report.IsConsistent())
+
+	type wrong struct {
+		Field2 IntField    `excel:"字段|字段2"`
+		Field1 StringField `excel:"字段|字段1"`
+		Field3 BoolField   `excel:"字段|字段3"`
+		Field4 TimeField   `excel:"字段|字段4"`
+		Field6 FloatField  `excel:"字段|字段6"`
+		Field7 FloatField  `excel:"字段|字段7"`
+	}
+	report, err = f.HeaderReport(new(wrong))
+	assert.Nil(t, err)
+	assert.False(t, report.IsConsistent())
+
+	issues := report.Sheets[0].Issues
+	assert.Len(t, issues, 4)
+	assert.Equal(t, HeaderMissing, issues[0].Kind)
+	assert.Equal(t, "E2", issues[0].Axis)
+	assert.Equal(t, HeaderMissing, issues[1].Kind)
+	assert.Equal(t, "F2", issues[1].Axis)
+	assert.Equal(t, HeaderUnexpected, issues[2].Kind)
+	assert.Equal(t, "E2", issues[2].Axis)
+	assert.Equal(t, HeaderMisordered, issues[3].Kind)
+	fmt.Println(report)
+
+	isConsistent, err := f.IsHeaderConsistent(new(wrong))
+	assert.Nil(t, err)
+	assert.False(t, isConsistent)
+}
+
+func newBannerExcel(t *testing.T) *bytes.Buffer {
+	f := excelize.NewFile()
+	assert.Nil(t, f.SetSheetRow("Sheet1", "A1", &[]interface{}{"2021年报表"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "B3", &[]interface{}{"字段"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "B4", &[]interface{}{"字段1", "字段2", "字段3", "字段4", "字段5", "备注"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "B5", &[]interface{}{"1", "1", "是", "2021-09-26", "1.2", "说明"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "B6", &[]interface{}{"2", "2", "否", "2021-09-26", "2.4"}))
+
+	buf, err := f.WriteToBuffer()
+	assert.Nil(t, err)
+	return buf
+}
+
+func TestExcel_HeaderRange(t *testing.T) {
+	for _, option := range []Option{HeaderRange("B3:F4"), DetectHeader(new(test))} {
+		f, err := NewExcelFromReader(newBannerExcel(t), option)
+		assert.Nil(t, err)
+
+		report, err := f.HeaderReport(new(test))
+		assert.Nil(t, err)
+		assert.True(t, report.IsConsistent(), report.String())
+
+		rows, err := f.GetRowsWithoutHeader()
+		assert.Nil(t, err)
+		assert.Len(t, rows, 2)
+		assert.Equal(t, "1", rows[0][1])
+	}
+
+	f, err := NewExcelFromReader(newBannerExcel(t), HeaderRange("B3:F4"), DataStartRow(6))
+	assert.Nil(t, err)
+	rows, err := f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 1)
+}
+
+func TestExcel_DetectHeaderMerged(t *testing.T) {
+	f := excelize.NewFile()
+	assert.Nil(t, f.SetSheetRow("Sheet1", "A1", &[]interface{}{"2021年报表"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "B3", &[]interface{}{"备注", "字段"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "C4", &[]interface{}{"字段1", "字段2", "字段3", "字段4", "字段5"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "B5", &[]interface{}{"说明", "1", "1", "是", "2021-09-26", "1.2"}))
+	assert.Nil(t, f.MergeCell("Sheet1", "B3", "B4"))
+	assert.Nil(t, f.MergeCell("Sheet1", "C3", "G3"))
+	buf, err := f.WriteToBuffer()
+	assert.Nil(t, err)
+
+	type mergedTest struct {
+		Note StringField `excel:"备注"`
+	}
+	n, err := NewExcelFromReader(buf, DetectHeader(new(test), new(mergedTest)))
+	assert.Nil(t, err)
+	importer, err := n.GetImporter("Sheet1")
+	assert.Nil(t, err)
+	assert.Len(t, importer.GetChildren(), 2)
+	rowIndexStart, rowIndexEnd := importer.GetChildren()[0].GetRowIndexPos()
+	assert.Equal(t, []int{3, 4}, []int{rowIndexStart, rowIndexEnd})
+
+	rows, err := n.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 1)
+	resp, note := new(test), new(mergedTest)
+	assert.Nil(t, n.ScanRow(rows[0], resp, note))
+	assert.Equal(t, int64(1), resp.Field2.GetStdValue())
+	assert.Equal(t, "说明", note.Note.GetStdValue())
+}
+
+func TestExcel_RowRules(t *testing.T) {
+	f := excelize.NewFile()
+	assert.Nil(t, f.SetSheetRow("Sheet1", "A1", &[]interface{}{"字段"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "A2", &[]interface{}{"字段1", "字段2", "字段3", "字段4", "字段5"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "A3", &[]interface{}{"1", "1", "是", "2021-09-26", "1.2"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "A5", &[]interface{}{"说明：以下为测试数据"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "A6", &[]interface{}{"2", "2", "否", "2021-09-26", "2.4"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "A7", &[]interface{}{"合计", "3", "", "", "3.6"}))
+	assert.Nil(t, f.SetSheetRow("Sheet1", "A8", &[]interface{}{"3", "3", "否", "2021-09-26", "2.4"}))
+	buf, err := f.WriteToBuffer()
+	assert.Nil(t, err)
+
+	e, err := NewExcelFromReader(buf, HeaderRow(2), SkipBlankRows(), SkipRow(CellHasPrefix("说明")), StopAtMarker("合计"))
+	assert.Nil(t, err)
+
+	rows, err := e.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 2)
+
+	var rowIndices []int
+	err = e.ScanSheetRows("Sheet1", func(rowIndex int, responses []interface{}, err error) error {
+		assert.Nil(t, err)
+		rowIndices = append(rowIndices, rowIndex)
+		return nil
+	}, new(test))
+	assert.Nil(t, err)
+	assert.Equal(t, []int{3, 6}, rowIndices)
+
+	// the rows are filtered by the getters only, so AsyncScanRows scans the rows as they are
+	count := 0
+	for row := range e.AsyncScanRows(append(rows, []string{"合计"}), new(test)) {
+		assert.Nil(t, row.Err)
+		count++
+	}
+	assert.Equal(t, 3, count)
+}
+
+func TestExcel_MergedCells(t *testing.T) {
+	var tests []interface{}
+	for i := 1; i <= 5; i++ {
+		tests = append(tests, &test{
+			Field1: NewStringField(fmt.Sprintf("%d", (i+1)/2)),
+			Field2: NewIntField(i),
+		})
+	}
+
+	f, err := NewExcelFromData(tests, MergeSameValues("字段|字段1"))
+	assert.Nil(t, err)
+
+	mergeCells, err := f.GetFile().GetMergeCells("Sheet1")
+	assert.Nil(t, err)
+	// the header and the two groups of 字段1
+	assert.Len(t, mergeCells, 3)
+
+	buf, err := f.GetFile().WriteToBuffer()
+	assert.Nil(t, err)
+	f, err = NewExcelFromReader(buf, HeaderRow(2), FillMergedCells())
+	assert.Nil(t, err)
+	rows, err := f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 5)
+	for i, row := range rows {
+		assert.Equal(t, fmt.Sprintf("%d", (i+2)/2), row[0])
+	}
+}
+
+func TestExcel_Formula(t *testing.T) {
+	type formula struct {
+		Field2 IntField     `excel:"字段|字段2"`
+		Field5 FloatField   `excel:"字段|字段5"`
+		Total  FormulaField `excel:"字段|合计"`
+	}
+
+	var rows []interface{}
+	for i := 1; i <= 3; i++ {
+		rows = append(rows, &formula{
+			Field2: NewIntField(i),
+			Field5: NewFloatField(1.5),
+			Total:  NewFormulaField("{字段2}*{字段|字段5}"),
+		})
+	}
+	f, err := NewExcelFromData(rows)
+	assert.Nil(t, err)
+	cellFormula, err := f.GetFile().GetCellFormula("Sheet1", "C3")
+	assert.Nil(t, err)
+	assert.Equal(t, "A3*B3", cellFormula)
+
+	buf, err := f.GetFile().WriteToBuffer()
+	assert.Nil(t, err)
+	f, err = NewExcelFromReader(buf, HeaderRow(2), EvalFormulas())
+	assert.Nil(t, err)
+	dataRows, err := f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+
+	resp := new(formula)
+	assert.Nil(t, f.ScanRow(dataRows[1], resp))
+	assert.Equal(t, "3", resp.Total.GetStdValue())
+
+	// the unexported fields are not formulas
+	type unexported struct {
+		Field2 IntField `excel:"字段2"`
+		note   string
+	}
+	_, err = NewExcelFromData([]interface{}{&unexported{Field2: NewIntField(1), note: "备注"}})
+	assert.Nil(t, err)
+}
+
+func TestExcel_Footer(t *testing.T) {
+	var tests []interface{}
+	for i := 1; i <= 5; i++ {
+		tests = append(tests, &test{
+			Field1: NewStringField(fmt.Sprintf("%d", i)),
+			Field2: NewIntField(i),
+			Field5: NewFloatField(float64(i) / 2),
+		})
+	}
+
+	f, err := NewExcelFromData(tests, SheetCount(2), Footer("合计", Sum("字段2"), Max("字段|字段5")))
+	assert.Nil(t, err)
+	formula, err := f.GetFile().GetCellFormula("Sheet2", "B6")
+	assert.Nil(t, err)
+	assert.Equal(t, "SUM(B3:B5)", formula)
+	title, err := f.GetFile().GetCellValue("Sheet1", "A5")
+	assert.Nil(t, err)
+	assert.Equal(t, "合计", title)
+
+	f, err = NewExcelFromData(tests, Footer("合计", Sum("字段2"), Average("字段|字段5")), FooterValues())
+	assert.Nil(t, err)
+	sum, err := f.GetFile().GetCellValue("Sheet1", "B8")
+	assert.Nil(t, err)
+	assert.Equal(t, "15", sum)
+	average, err := f.GetFile().GetCellValue("Sheet1", "E8")
+	assert.Nil(t, err)
+	assert.Equal(t, "1.5", average)
+}
+
+func TestExcel_GroupBy(t *testing.T) {
+	var tests []interface{}
+	for i, key := range []string{"a", "a", "b"} {
+		tests = append(tests, &test{
+			Field1: NewStringField(key),
+			Field2: NewIntField(i + 1),
+		})
+	}
+
+	f, err := NewExcelFromData(tests, GroupBy([]string{"字段1"}, Sum("字段2")), MergeGroupKeys(), Footer("合计", Sum("字段2")))
+	assert.Nil(t, err)
+
+	ex := f.GetFile()
+	title, err := ex.GetCellValue("Sheet1", "A5")
+	assert.Nil(t, err)
+	assert.Equal(t, "a 小计", title)
+	formula, err := ex.GetCellFormula("Sheet1", "B5")
+	assert.Nil(t, err)
+	assert.Equal(t, "SUBTOTAL(9,B3:B4)", formula)
+	formula, err = ex.GetCellFormula("Sheet1", "B8")
+	assert.Nil(t, err)
+	assert.Equal(t, "SUBTOTAL(9,B3:B7)", formula)
+
+	level, err := ex.GetRowOutlineLevel("Sheet1", 6)
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(1), level)
+	level, err = ex.GetRowOutlineLevel("Sheet1", 7)
+	assert.Nil(t, err)
+	assert.Equal(t, uint8(0), level)
+
+	mergeCells, err := ex.GetMergeCells("Sheet1")
+	assert.Nil(t, err)
+	assert.Len(t, mergeCells, 2)
+}
+
+func TestExcel_SheetPartition(t *testing.T) {
+	var tests []interface{}
+	for i, key := range []string{"华东", "华北", "华东", "华南", "华北"} {
+		tests = append(tests, &test{
+			Field1: NewStringField(key),
+			Field2: NewIntField(i + 1),
+		})
+	}
+
+	f, err := NewExcelFromData(tests, SplitByField("字段|字段1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"华东", "华北", "华南"}, f.GetFile().GetSheetList())
+	rows, err := f.GetFile().GetRows("华北")
+	assert.Nil(t, err)
+	assert.Len(t, rows, 4)
+
+	f, err = NewExcelFromData(tests, MaxRowsPerSheet(2), SheetPrefix("Page"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Page1", "Page2", "Page3"}, f.GetFile().GetSheetList())
+
+	// the header is excluded from the limit unless NoHeader is set
+	limit, err := f.sheetRowsLimit(tests)
+	assert.Nil(t, err)
+	assert.Equal(t, _maxSheetRows-2, limit)
+	f, err = NewExcelFromData(tests, NoHeader(), Footer("合计"))
+	assert.Nil(t, err)
+	limit, err = f.sheetRowsLimit(tests)
+	assert.Nil(t, err)
+	assert.Equal(t, _maxSheetRows-1, limit)
+
+	// the subtotal rows are counted, 华东 and its subtotal, 华北 and its subtotal, and 华东 and its subtotal are 6 rows
+	g := newExcel()
+	GroupBy([]string{"字段|字段1"}, Sum("字段|字段2"))(g)
+	parts, err := g.splitRows(tests, 0, 6)
+	assert.Nil(t, err)
+	assert.Len(t, parts, 2)
+	assert.Len(t, parts[0].rows, 3)
+	assert.Len(t, parts[1].rows, 2)
+
+	f, err = NewExcelFromData(tests, SplitBy(func(row interface{}) string {
+		return fmt.Sprintf("第%d组", row.(*test).Field2.GetStdValue()%2)
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"第1组", "第0组"}, f.GetFile().GetSheetList())
+
+	// the names which are the same after sanitized are not merged
+	long := strings.Repeat("长", _maxSheetNameLength)
+	keys := []string{"a/b", "a:b", "A/B", "a/b", long, long + "?"}
+	var rowKeys []interface{}
+	for i := range keys {
+		rowKeys = append(rowKeys, &test{Field1: NewStringField(keys[i])})
+	}
+	f, err = NewExcelFromData(rowKeys, SplitByField("字段|字段1"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a_b", "a_b_2", "A_B_3", long, long[:len(long)-2*len("长")] + "_2"}, f.GetFile().GetSheetList())
+	rows, err = f.GetFile().GetRows("a_b")
+	assert.Nil(t, err)
+	assert.Len(t, rows, 4)
+}
+
+func TestWorkbook_Build(t *testing.T) {
+	type item struct {
+		OrderNo StringField `excel:"订单|订单号"`
+		Name    StringField `excel:"明细|名称"`
+		Amount  FloatField  `excel:"明细|金额"`
+	}
+
+	orders := []interface{}{&test{Field1: NewStringField("1")}, &test{Field1: NewStringField("2")}}
+	items := []interface{}{
+		&item{OrderNo: NewStringField("1"), Name: NewStringField("a"), Amount: NewFloatField(1.5)},
+		&item{OrderNo: NewStringField("1"), Name: NewStringField("b"), Amount: NewFloatField(2)},
+	}
+
+	f, err := NewWorkbook().
+		AddSheet("订单", orders).
+		AddSheet("明细", items, HeaderStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}), Footer("合计", Sum("金额"))).
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"订单", "明细"}, f.GetFile().GetSheetList())
+
+	rows, err := f.GetFile().GetRows("明细")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"订单", "明细", ""}, rows[0])
+	assert.Equal(t, []string{"订单号", "名称", "金额"}, rows[1])
+	assert.Len(t, rows, 5)
+
+	_, err = NewWorkbook().AddSheet("订单", orders).AddSheet("订单", items).Build()
+	assert.NotNil(t, err)
+	_, err = NewWorkbook().AddSheet("Orders", orders).AddSheet("orders", items).Build()
+	assert.EqualError(t, err, "sheet name orders is empty or duplicated")
+}
+
+type order struct {
+	OrderNo StringField `excel:"订单|订单号"`
+	Count   IntField    `excel:"订单|数量"`
+}
+
+type orderItem struct {
+	OrderNo StringField `excel:"明细|订单号"`
+	Name    StringField `excel:"明细|名称"`
+}
+
+func newOrderExcel(t *testing.T) *bytes.Buffer {
+	f, err := NewWorkbook().
+		AddSheet("订单", []interface{}{
+			&order{OrderNo: NewStringField("1"), Count: NewIntField(2)},
+			&order{OrderNo: NewStringField("2"), Count: NewIntField(1)},
+		}).
+		AddSheet("明细", []interface{}{
+			&orderItem{OrderNo: NewStringField("1"), Name: NewStringField("a")},
+			&orderItem{OrderNo: NewStringField("1"), Name: NewStringField("b")},
+			&orderItem{OrderNo: NewStringField("3"), Name: NewStringField("c")},
+		}).
+		Build()
+	assert.Nil(t, err)
+	assert.Nil(t, f.GetFile().SetCellValue("订单", "B4", "x"))
+
+	buf, err := f.GetFile().WriteToBuffer()
+	assert.Nil(t, err)
+	return buf
+}
+
+func TestExcel_ScanSheets(t *testing.T) {
+	f, err := NewExcelFromReader(newOrderExcel(t), HeaderRow(2))
+	assert.Nil(t, err)
+
+	var (
+		orders []*order
+		items  []*orderItem
+	)
+	result, err := f.ScanSheets(SheetBinding{Sheet: "订单", Dest: &orders}, SheetBinding{Sheet: "明细", Dest: &items})
+	assert.Nil(t, err)
+	assert.True(t, result.HasErrors())
+	assert.Len(t, orders, 1)
+	assert.Len(t, items, 3)
+	assert.Equal(t, "b", items[1].Name.GetStdValue())
+
+	assert.Len(t, result.Errors["订单"], 1)
+	cellErr, ok := result.Errors["订单"][0].(*CellError)
+	assert.True(t, ok)
+	assert.Equal(t, 4, cellErr.RowIndex())
+	assert.Equal(t, 2, cellErr.ColIndex())
+
+	_, err = f.ScanSheets(SheetBinding{Sheet: "订单", Dest: orders})
+	assert.NotNil(t, err)
+}
+
+func TestSheetsResult_Link(t *testing.T) {
+	type linkedOrder struct {
+		OrderNo StringField `excel:"订单|订单号"`
+		Count   IntField    `excel:"订单|数量"`
+		Items   []*orderItem
+	}
+
+	f, err := NewExcelFromReader(newOrderExcel(t), HeaderRow(2))
+	assert.Nil(t, err)
+
+	var (
+		orders []*linkedOrder
+		items  []*orderItem
+	)
+	result, err := f.ScanSheets(SheetBinding{Sheet: "订单", Dest: &orders}, SheetBinding{Sheet: "明细", Dest: &items})
+	assert.Nil(t, err)
+
+	errs, err := result.Link(Relation{Parent: "订单", ParentKey: "订单号", Child: "明细", ChildKey: "明细|订单号", Attach: "Items"})
+	assert.Nil(t, err)
+	assert.Len(t, errs, 1)
+	cellErr := errs[0].(*CellError)
+	assert.Equal(t, 5, cellErr.RowIndex())
+	assert.Equal(t, 1, cellErr.ColIndex())
+	assert.Len(t, result.Errors["明细"], 1)
+	assert.Len(t, orders[0].Items, 2)
+
+	// the children are not attached twice
+	_, err = result.Link(Relation{Parent: "订单", ParentKey: "订单号", Child: "明细", ChildKey: "明细|订单号", Attach: "Items"})
+	assert.EqualError(t, err, "relation from sheet 明细 to sheet 订单 is linked")
+	assert.Len(t, orders[0].Items, 2)
+	assert.Len(t, result.Errors["明细"], 1)
+
+	_, err = result.Link(Relation{Parent: "订单", ParentKey: "订单号", Child: "明细", ChildKey: "订单号", Attach: "Count"})
+	assert.NotNil(t, err)
+}
+
+func TestExcel_CSV(t *testing.T) {
+	for _, options := range [][]Option{
+		{CSVEncoding(EncodingUTF8BOM)},
+		{CSVEncoding(EncodingGBK), CSVDelimiter('\t'), CSVQuoteAll()},
+	} {
+		f, err := NewExcelFromFile("./test/test.xlsx", options...)
+		assert.Nil(t, err)
+
+		var buf bytes.Buffer
+		assert.Nil(t, f.WriteCSV(&buf, "Sheet2"))
+
+		c, err := NewExcelFromCSV(&buf, append(options, HeaderRow(2))...)
+		assert.Nil(t, err)
+		isConsistent, err := c.IsHeaderConsistent(new(test))
+		assert.Nil(t, err)
+		assert.True(t, isConsistent)
+
+		rows, err := c.GetRowsWithoutHeader()
+		assert.Nil(t, err)
+		assert.Len(t, rows, 3)
+		resp := new(test)
+		assert.Nil(t, c.ScanRow(rows[0], resp))
+		assert.Equal(t, "3", resp.Field1.GetStdValue())
+	}
+
+	c, err := NewExcelFromCSV(strings.NewReader("编号,名称\n1,\"a\"b\"\n"), CSVLazyQuotes())
+	assert.Nil(t, err)
+	rows, err := c.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"1", `a"b`}}, rows)
+}
+
+func TestExcel_XLS(t *testing.T) {
+	f, err := NewExcelFromFile("./test/test.xls", ActiveSheet("Sheet1"), HeaderRow(2))
+	assert.Nil(t, err)
+	isConsistent, err := f.IsHeaderConsistent(new(test))
+	assert.Nil(t, err)
+	assert.True(t, isConsistent)
+
+	rows, err := f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{
+		{"1", "2", "是", "2021-09-26", "1.2"},
+		{"2", "3", "否", "2021-09-27", "2.5"},
+		{"3", "4", "TRUE", "2021-09-28", "3"},
+	}, rows)
+
+	resp := new(test)
+	assert.Nil(t, f.ScanRow(rows[0], resp))
+	assert.Equal(t, int64(2), resp.Field2.GetStdValue())
+	assert.True(t, resp.Field3.GetStdValue())
+	assert.Equal(t, "2021-09-26", resp.Field4.GetStdValue().Format(_dateLayout))
+
+	// the merged cells are loaded for the header detection
+	mergeCells, err := f.GetFile().GetMergeCells("Sheet1")
+	assert.Nil(t, err)
+	assert.Len(t, mergeCells, 1)
+	assert.Equal(t, "A1", mergeCells[0].GetStartAxis())
+	assert.Equal(t, "E1", mergeCells[0].GetEndAxis())
+
+	// the shared strings are continued in several records
+	f, err = NewExcelFromFile("./test/test.xls", ActiveSheet("长文本"), HeaderRow(1))
+	assert.Nil(t, err)
+	rows, err = f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 300)
+	assert.Equal(t, "300", rows[299][0])
+	assert.True(t, strings.HasPrefix(rows[298][1], "第299行：长文本内容"))
+	assert.Equal(t, 20, strings.Count(rows[298][1], "长文本内容"))
+	assert.Equal(t, "row 298: "+strings.Repeat("long text ", 10), rows[297][1])
+}
+
+func TestExcel_ODS(t *testing.T) {
+	f, err := NewExcelFromFile("./test/test.xlsx", HeaderRow(2))
+	assert.Nil(t, err)
+	rows, err := f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, f.WriteODS(&buf))
+
+	o, err := NewExcelFromODS(&buf, HeaderRow(2))
+	assert.Nil(t, err)
+	isConsistent, err := o.IsHeaderConsistent(new(test))
+	assert.Nil(t, err)
+	assert.True(t, isConsistent)
+	odsRows, err := o.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Equal(t, rows, odsRows)
+	mergeCells, err := o.GetFile().GetMergeCells("Sheet2")
+	assert.Nil(t, err)
+	assert.Len(t, mergeCells, 1)
+
+	// the repeated cells, the typed values and the comments written by LibreOffice
+	content := `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+ xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:spreadsheet><table:table table:name="数据">
+<table:table-row><table:table-cell table:number-columns-spanned="2" office:value-type="string"><text:p>字段</text:p></table:table-cell><table:covered-table-cell/></table:table-row>
+<table:table-row><table:table-cell office:value-type="string"><text:p>字段1</text:p></table:table-cell><table:table-cell office:value-type="string"><text:p>字段2</text:p></table:table-cell></table:table-row>
+<table:table-row table:number-rows-repeated="2"><table:table-cell office:value-type="float" office:value="1.50"><text:p>1.5</text:p></table:table-cell><table:table-cell office:value-type="date" office:date-value="2021-09-26"><text:p>09/26/21</text:p><office:annotation><text:p>备注</text:p></office:annotation></table:table-cell></table:table-row>
+<table:table-row><table:table-cell table:number-columns-repeated="2" office:value-type="string"><text:p>a<text:s text:c="2"/>b</text:p><text:p>c</text:p></table:table-cell></table:table-row>
+<table:table-row><table:table-cell office:value-type="date" office:date-value="2021-09-26T10:30:00.123456"/><table:table-cell office:value-type="date" office:date-value="2021-09-26T10:30:00.5+08:00"/></table:table-row>
+<table:table-row table:number-rows-repeated="1048570"><table:table-cell table:number-columns-repeated="1024"/></table:table-row>
+</table:table></office:spreadsheet></office:body></office:document-content>`
+	buf.Reset()
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("content.xml")
+	assert.Nil(t, err)
+	_, _ = w.Write([]byte(content))
+	assert.Nil(t, zw.Close())
+
+	o, err = NewExcelFromODS(&buf, HeaderRow(2))
+	assert.Nil(t, err)
+	odsRows, err = o.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"1.5", "2021-09-26"}, {"1.5", "2021-09-26"}, {"a  b\nc", "a  b\nc"}, {"2021-09-26 10:30:00", "2021-09-26 10:30:00"}}, odsRows)
+
+	// the spaces and the tabs are kept
+	c, err := NewExcelFromCSV(strings.NewReader("编号,名称\n007,\" a  b\tc \"\n"))
+	assert.Nil(t, err)
+	buf.Reset()
+	assert.Nil(t, c.WriteODS(&buf))
+	o, err = NewExcelFromODS(&buf, HeaderRow(1))
+	assert.Nil(t, err)
+	odsRows, err = o.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"007", " a  b\tc "}}, odsRows)
+}
+
+func TestExcel_JSON(t *testing.T) {
+	f, err := NewExcelFromFile("./test/test.xlsx", HeaderRow(2))
+	assert.Nil(t, err)
+	rows, err := f.GetSheetRowsWithoutHeader("Sheet1")
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	assert.Nil(t, f.WriteJSON(&buf, "Sheet1"))
+	assert.True(t, strings.HasPrefix(buf.String(), `[`+"\n"+`{"字段":{"字段1":"`))
+
+	j, err := NewExcelFromJSON(&buf)
+	assert.Nil(t, err)
+	isConsistent, err := j.IsHeaderConsistent(new(test))
+	assert.Nil(t, err)
+	assert.True(t, isConsistent)
+	jsonRows, err := j.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Equal(t, rows, jsonRows)
+	mergeCells, err := j.GetFile().GetMergeCells("Sheet1")
+	assert.Nil(t, err)
+	assert.Len(t, mergeCells, 1)
+
+	buf.Reset()
+	assert.Nil(t, j.WriteNDJSON(&buf, ""))
+	assert.Equal(t, len(rows), strings.Count(buf.String(), "\n"))
+	n, err := NewExcelFromNDJSON(&buf)
+	assert.Nil(t, err)
+	jsonRows, err = n.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Equal(t, rows, jsonRows)
+
+	// the keys are merged in order of first appearance, and the values of different depth
+	n, err = NewExcelFromNDJSON(strings.NewReader(`{"编号":1,"订单":{"金额":1.5}}
+{"订单":{"金额":2,"备注":null},"编号":2,"标签":["a","b"]}`))
+	assert.Nil(t, err)
+	headers, err := n.GetFile().GetRows("Sheet1")
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{
+		{"编号", "订单", "", "标签"},
+		{"", "金额", "备注"},
+		{"1", "1.5"},
+		{"2", "2", "", `["a","b"]`},
+	}, headers)
+	jsonRows, err = n.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Len(t, jsonRows, 2)
+
+	_, err = NewExcelFromNDJSON(strings.NewReader(`{"订单":1}` + "\n" + `{"订单":{"金额":2}}`))
+	assert.NotNil(t, err)
+
+	// the new keys of a group are inserted among the written columns, and a null key can become a group
+	n, err = NewExcelFromNDJSON(strings.NewReader(`{"a":{"x":1},"b":2,"n":null}
+{"a":{"y":3},"c":4}
+{"n":{"m":5},"b":6}`))
+	assert.Nil(t, err)
+	headers, err = n.GetFile().GetRows("Sheet1")
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{
+		{"a", "", "b", "n", "c"},
+		{"x", "y", "", "m"},
+		{"1", "", "2"},
+		{"", "3", "", "", "4"},
+		{"", "", "6", "5"},
+	}, headers)
+	_, err = NewExcelFromNDJSON(strings.NewReader(`{"n":null}` + "\n" + `{"n":1}` + "\n" + `{"n":{"m":2}}`))
+	assert.NotNil(t, err)
+}
+
+func TestExcel_GenerateStruct(t *testing.T) {
+	f, err := NewExcelFromFile("./test/test.xlsx", HeaderRow(2))
+	assert.Nil(t, err)
+	code, err := f.GenerateStruct("Sheet1", "Test")
+	assert.Nil(t, err)
+	assert.Contains(t, string(code), "ColA excel.IntField   `excel:\"字段|字段1\"` // A2\n")
+	assert.Contains(t, string(code), "ColD excel.TimeField  `excel:\"字段|字段4\"` // D2\n")
+
+	// the ASCII titles are the field names
+	j, err := NewExcelFromNDJSON(strings.NewReader(`{"order":{"order id":"1","2nd name":"a"},"amount":"1.5","ok":"是","id":"1"}
+{"order":{"order id":"9999999999","2nd name":"b"},"amount":"2","ok":"否","id":"x"}`))
+	assert.Nil(t, err)
+	code, err = j.GenerateStruct("Sheet1", "Order")
+	assert.Nil(t, err)
+	assert.Equal(t, "type Order struct {\n"+
+		"\tOrderId excel.Int64Field  `excel:\"order|order id\"` // A2\n"+
+		"\tColB    excel.StringField `excel:\"order|2nd name\"` // B2\n"+
+		"\tAmount  excel.FloatField  `excel:\"amount\"`         // C1\n"+
+		"\tOk      excel.BoolField   `excel:\"ok\"`             // D1\n"+
+		"\tId      excel.StringField `excel:\"id\"`             // E1\n"+
+		"}\n", string(code))
+}
+
+func TestSpec(t *testing.T) {
+	spec, err := LoadSpec(strings.NewReader(`
+sheet: 订单
+columns:
+  - path: 订单|编号
+    name: id
+    type: int
+    required: true
+    min: 1
+  - path: 订单|状态
+    name: status
+    enum: {待支付: unpaid, 已支付: paid}
+  - path: 订单|日期
+    name: date
+    type: time
+    format: 2006/01/02
+  - path: 金额
+    name: amount
+    type: float
+    default: "0"
+  - path: 备注
+    name: remark
+    pattern: ^[a-z]*$
+`))
+	assert.Nil(t, err)
+
+	date := time.Date(2021, 9, 26, 0, 0, 0, 0, time.Local)
+	f, err := NewExcelFromRecords(spec, []Record{
+		{"id": 1, "amount": 1.5, "status": "paid", "date": date, "remark": "ok"},
+		{"id": 2, "status": "unpaid"},
+	})
+	assert.Nil(t, err)
+	rows, err := f.GetFile().GetRows("订单")
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{
+		{"订单", "", "", "金额", "备注"},
+		{"编号", "状态", "日期"},
+		{"1", "已支付", "2021/09/26", "1.5", "ok"},
+		{"2", "待支付"},
+	}, rows)
+
+	isConsistent, err := f.IsHeaderConsistent(spec.Response())
+	assert.Nil(t, err)
+	assert.True(t, isConsistent)
+
+	var records []Record
+	err = f.ScanSheetRecords("", spec, func(rowIndex int, record Record, err error) error {
+		assert.Nil(t, err)
+		records = append(records, record)
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []Record{
+		{"id": 1, "amount": 1.5, "status": "paid", "date": date, "remark": "ok"},
+		{"id": 2, "amount": float64(0), "status": "unpaid", "date": nil, "remark": nil},
+	}, records)
+
+	for _, row := range [][]string{
+		{"", "已支付"},
+		{"0", "已支付"},
+		{"1", "已取消"},
+		{"1", "已支付", "2021-09-26"},
+		{"1", "已支付", "", "", "OK"},
+	} {
+		_, err = f.ScanRecord(row, spec)
+		var cellErr *CellError
+		assert.True(t, errors.As(err, &cellErr))
+	}
+
+	// the time of day is kept by the format
+	spec.Columns[2].Format = "2006/01/02 15:04"
+	assert.Nil(t, spec.Compile())
+	record, err := f.ScanRecord([]string{"1", "已支付", "2021/09/26 10:30"}, spec)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2021, 9, 26, 10, 30, 0, 0, time.Local), record["date"])
+
+	// the header row of the spec is applied by SpecHeader, and checked by the scans
+	spec.HeaderRow = 2
+	f, err = NewExcelFromFile("./test/test.xlsx", SpecHeader(spec))
+	assert.Nil(t, err)
+	importer, err := f.GetImporter("Sheet1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, importer.getRowsBeginIndex())
+	spec.HeaderRow = 3
+	_, err = f.ScanRecord(nil, spec)
+	assert.EqualError(t, err, "header of sheet Sheet1 ends at row 2, not the header row 3 of the spec")
+
+	// an enum value mapped by two labels is ambiguous
+	spec.Columns[1].Enum = map[string]string{"待支付": "unpaid", "未支付": "unpaid"}
+	assert.NotNil(t, spec.Compile())
+}
+
+func TestExcel_Columns(t *testing.T) {
+	columns := NewColumns().Group("字段", func(g *Columns) {
+		g.Col("字段1", func(row interface{}) interface{} { return row.(*test).Field1.GetStdValue() })
+		g.Col("字段2", func(row interface{}) interface{} { return row.(*test).Field2.GetStdValue() })
+		g.Col("字段3", func(row interface{}) interface{} {
+			if row.(*test).Field3.GetStdValue() {
+				return "是"
+			}
+			return "否"
+		})
+		g.Col("字段4", func(row interface{}) interface{} { return row.(*test).Field4 })
+		g.Col("字段5", func(row interface{}) interface{} { return row.(*test).Field5.GetStdValue() }).Format("0.00")
+	})
+	h, err := parseHeader(new(test))
+	assert.Nil(t, err)
+	assert.Equal(t, h, columns.header())
+
+	var tests []interface{}
+	for i := 1; i <= 3; i++ {
+		tests = append(tests, &test{
+			Field1: NewStringField(fmt.Sprintf("%d", i)),
+			Field2: NewIntField(i),
+			Field3: NewBoolField(i%2 == 1),
+			Field4: NewTimeField(time.Date(2021, 9, 25+i, 0, 0, 0, 0, time.Local)),
+			Field5: NewFloatField(1.5 * float64(i)),
+		})
+	}
+	f, err := NewExcelFromColumns(columns, tests)
+	assert.Nil(t, err)
+	isConsistent, err := f.IsHeaderConsistent(new(test))
+	assert.Nil(t, err)
+	assert.True(t, isConsistent)
+	importer, err := f.GetImporter("Sheet1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, f.getRowsBeginIndex(importer))
+	assert.Equal(t, []string{"字段", "字段5"}, importer.SubImporter("字段|字段5").GetPath())
+	colIndexStart, colIndexEnd := importer.SubImporter("字段|字段5").GetColIndexPos()
+	assert.Equal(t, []int{5, 5}, []int{colIndexStart, colIndexEnd})
+
+	_, err = f.GetRowsWithoutHeader()
+	assert.NotNil(t, err)
+
+	// a shallow leaf is stretched to the last header row
+	mixedColumns := NewColumns()
+	mixedColumns.Col("备注", func(row interface{}) interface{} { return "" })
+	mixedColumns.Group("字段", func(g *Columns) {
+		g.Col("字段1", func(row interface{}) interface{} { return row.(*test).Field1.GetStdValue() })
+	})
+	mixed, err := NewExcelFromColumns(mixedColumns, tests)
+	assert.Nil(t, err)
+	importer, err = mixed.GetImporter("Sheet1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, mixed.getRowsBeginIndex(importer))
+	rowIndexStart, rowIndexEnd := importer.GetChildren()[0].GetRowIndexPos()
+	assert.Equal(t, []int{1, 2}, []int{rowIndexStart, rowIndexEnd})
+
+	buf, err := f.GetFile().WriteToBuffer()
+	assert.Nil(t, err)
+	f, err = NewExcelFromReader(buf, HeaderRow(2))
+	assert.Nil(t, err)
+	mergeCells, err := f.GetFile().GetMergeCells("Sheet1")
+	assert.Nil(t, err)
+	assert.Len(t, mergeCells, 1)
+
+	rows, err := f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, []string{"2", "2", "否", "2021-09-27", "3"}, rows[1])
+	styleId, err := f.GetFile().GetCellStyle("Sheet1", "E4")
+	assert.Nil(t, err)
+	assert.NotZero(t, styleId)
+	resp := new(test)
+	assert.Nil(t, f.ScanRow(rows[1], resp))
+	assert.Equal(t, int64(2), resp.Field2.GetStdValue())
+	assert.Equal(t, 3.0, resp.Field5.GetStdValue())
+
+	_, err = NewExcelFromColumns(NewColumns(), nil)
+	assert.NotNil(t, err)
+}
+
+func TestImporter_Bind(t *testing.T) {
+	f, err := NewExcelFromFile("./test/test.xlsx", HeaderRow(2))
+	assert.Nil(t, err)
+	importer, err := f.GetImporter("Sheet1")
+	assert.Nil(t, err)
+
+	assert.Nil(t, importer.Bind(reflect.TypeOf(&test{})))
+	b, err := importer.bind(reflect.TypeOf(test{}))
+	assert.Nil(t, err)
+	assert.Len(t, b.fields, 5)
+	cached, err := importer.bind(reflect.TypeOf(test{}))
+	assert.Nil(t, err)
+	assert.True(t, b == cached)
+
+	type invalid struct {
+		Field1 StringField `excel:"字段|字段1"`
+		Field2 string      `excel:"字段|字段2"`
+	}
+	err = f.Bind(reflect.TypeOf(invalid{}))
+	assert.EqualError(t, err, "sheet Sheet1: field invalid.Field2 is string, not a Field")
+
+	rows, err := f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.EqualError(t, f.ScanRow(rows[0], test{}), "response excel.test is not a struct pointer")
+	assert.EqualError(t, f.ScanRow(rows[0], new(invalid)), "field invalid.Field2 is string, not a Field")
+	err = f.ScanSheetRows("Sheet1", func(int, []interface{}, error) error { return nil }, new(invalid))
+	assert.NotNil(t, err)
+	for res := range f.AsyncScanRows(rows, test{}) {
+		assert.NotNil(t, res.Err)
+	}
+}
+
+func TestExcel_ColumnOffsets(t *testing.T) {
+	type offsetTest struct {
+		No   IntField    `excel:"编号"`
+		Name StringField `excel:"名称"`
+	}
+
+	ex := excelize.NewFile()
+	for axis, value := range map[string]string{
+		"B1": "编号", "D1": "名称",
+		"A2": "序号1", "B2": "1", "C2": "x", "D2": "a", "E2": "备注",
+		"B3": "2", "D3": "b",
+		"C4": "spacer", "D4": "c",
+	} {
+		assert.Nil(t, ex.SetCellStr("Sheet1", axis, value))
+	}
+	buf, err := ex.WriteToBuffer()
+	assert.Nil(t, err)
+
+	f, err := NewExcelFromReader(buf, HeaderRow(1))
+	assert.Nil(t, err)
+	rows, err := f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Len(t, rows, 3)
+
+	resp := new(offsetTest)
+	assert.Nil(t, f.ScanRow(rows[0], resp))
+	assert.Equal(t, int64(1), resp.No.GetStdValue())
+	assert.Equal(t, "a", resp.Name.GetStdValue())
+	resp = new(offsetTest)
+	assert.Nil(t, f.ScanRow(rows[1], resp))
+	assert.Equal(t, int64(2), resp.No.GetStdValue())
+	assert.Equal(t, "b", resp.Name.GetStdValue())
+
+	// the blank cell of a leaf is not read from the unlabelled column beside it
+	type gapTest struct {
+		No     IntField    `excel:"编号"`
+		NoText StringField `excel:"编号"`
+		Spacer StringField `excel:"col=C"`
+	}
+	gap := new(gapTest)
+	assert.Nil(t, f.ScanRow(rows[2], gap))
+	assert.Equal(t, int64(0), gap.No.GetStdValue())
+	assert.Equal(t, "", gap.NoText.GetStdValue())
+	assert.Equal(t, "spacer", gap.Spacer.GetStdValue())
+
+	spec := &Spec{Columns: []*SpecColumn{{Path: "编号", Type: "int"}, {Path: "名称"}}}
+	assert.Nil(t, spec.Compile())
+	record, err := f.ScanRecord(rows[0], spec)
+	assert.Nil(t, err)
+	assert.Equal(t, Record{"编号": 1, "名称": "a"}, record)
+}
+
+func TestExcel_LazyImporters(t *testing.T) {
+	ex := excelize.NewFile()
+	ex.NewSheet("无表头")
+	for axis, value := range map[string]string{
+		"A1": "编号", "B1": "名称",
+		"A2": "1", "B2": "a", "D2": "备注",
+	} {
+		assert.Nil(t, ex.SetCellStr("Sheet1", axis, value))
+	}
+	buf, err := ex.WriteToBuffer()
+	assert.Nil(t, err)
+
+	// the header of the other sheet is not found, but it's not built until accessed
+	type lazyTest struct {
+		No IntField `excel:"编号"`
+	}
+	f, err := NewExcelFromReader(bytes.NewReader(buf.Bytes()), DetectHeader(new(lazyTest)))
+	assert.Nil(t, err)
+	assert.Equal(t, []*Importer{nil, nil}, f.importers)
+	_, err = f.GetImporter("Sheet1")
+	assert.Nil(t, err)
+	assert.Nil(t, f.importers[1])
+	_, err = f.GetImporter("无表头")
+	assert.NotNil(t, err)
+	_, err = f.GetImporter("不存在")
+	assert.EqualError(t, err, "sheet name 不存在 is not active")
+
+	// the columns are bounded by the header row, not the data rows
+	f, err = NewExcelFromReader(bytes.NewReader(buf.Bytes()), ActiveSheet("Sheet1"), HeaderRow(1))
+	assert.Nil(t, err)
+	importer, err := f.GetImporter("Sheet1")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, importer.colIndexEnd)
+}
+
+func TestExcel_PositionTags(t *testing.T) {
+	type feed struct {
+		No   IntField    `excel:"#1"`
+		Name StringField `excel:"col=C"`
+	}
+	type noted struct {
+		No   IntField    `excel:"编号"`
+		Name StringField `excel:"名称"`
+		Note StringField `excel:"col=D"`
+	}
+
+	var rows []interface{}
+	for i := 1; i <= 3; i++ {
+		rows = append(rows, &feed{No: NewIntField(i), Name: NewStringField(fmt.Sprintf("名称%d", i))})
+	}
+	f, err := NewExcelFromData(rows, NoHeader())
+	assert.Nil(t, err)
+	cells, err := f.GetFile().GetRows("Sheet1")
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"1", "", "名称1"}, {"2", "", "名称2"}, {"3", "", "名称3"}}, cells)
+
+	dataRows, err := f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	assert.Len(t, dataRows, 3)
+	resp := new(feed)
+	assert.Nil(t, f.ScanRow(dataRows[1], resp))
+	assert.Equal(t, int64(2), resp.No.GetStdValue())
+	assert.Equal(t, "名称2", resp.Name.GetStdValue())
+	for res := range f.AsyncScanRows(dataRows, new(feed)) {
+		assert.Nil(t, res.Err)
+		assert.NotEmpty(t, res.Responses[0].(*feed).Name.GetStdValue())
+	}
+
+	// the trailing column without header is picked up by a header template
+	f, err = NewExcelFromData([]interface{}{&noted{No: NewIntField(1), Name: NewStringField("a"), Note: NewStringField("备注")}})
+	assert.Nil(t, err)
+	cells, err = f.GetFile().GetRows("Sheet1")
+	assert.Nil(t, err)
+	assert.Equal(t, [][]string{{"编号", "名称"}, {"1", "a", "", "备注"}}, cells)
+	f, err = NewExcelFromReader(bytes.NewReader(mustWriteBuffer(t, f)), HeaderRow(1))
+	assert.Nil(t, err)
+	isConsistent, err := f.IsHeaderConsistent(new(noted))
+	assert.Nil(t, err)
+	assert.True(t, isConsistent)
+	dataRows, err = f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	note := new(noted)
+	assert.Nil(t, f.ScanRow(dataRows[0], note))
+	assert.Equal(t, "备注", note.Note.GetStdValue())
+
+	type invalid struct {
+		No IntField `excel:"col=1"`
+	}
+	assert.NotNil(t, f.ScanRow(dataRows[0], new(invalid)))
+	type overlapped struct {
+		No   IntField `excel:"编号"`
+		Note IntField `excel:"#1"`
+	}
+	_, err = NewExcelFromData([]interface{}{new(overlapped)})
+	assert.NotNil(t, err)
+}
+
+func mustWriteBuffer(t *testing.T, f *Excel) []byte {
+	buf, err := f.GetFile().WriteToBuffer()
+	assert.Nil(t, err)
+	return buf.Bytes()
+}
+
+type hookTest struct {
+	Field1 StringField `excel:"字段|字段1"`
+	Field2 IntField    `excel:"字段|字段2"`
+	Label  StringField `excel:"字段|标签"`
+}
+
+func (h *hookTest) BeforeScan(raw []string) error {
+	raw[0] = strings.TrimPrefix(raw[0], "#")
+	return nil
+}
+
+func (h *hookTest) AfterScan() error {
+	h.Label = NewStringField(fmt.Sprintf("%s-%d", h.Field1.GetStdValue(), h.Field2.GetStdValue()))
+	return nil
+}
+
+func (h *hookTest) Validate() error {
+	if h.Field2.GetStdValue() > 2 {
+		return NewCellError("字段2", errors.New("不能大于2"))
+	}
+	return nil
+}
+
+func (h *hookTest) BeforeExport() {
+	h.Field1 = NewStringField("#" + h.Field1.GetStdValue())
+}
+
+func TestExcel_Hooks(t *testing.T) {
+	var exported []int
+	var rows []interface{}
+	for i := 1; i <= 3; i++ {
+		rows = append(rows, &hookTest{
+			Field1: NewStringField(fmt.Sprintf("%d", i)),
+			Field2: NewIntField(i),
+		})
+	}
+	f, err := NewExcelFromData(rows, HeaderRow(2), Use(func(ctx *RowContext, next func() error) error {
+		assert.True(t, ctx.Export)
+		exported = append(exported, ctx.RowIndex)
+		return next()
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, []int{3, 4, 5}, exported)
+	assert.Equal(t, "#1", rows[0].(*hookTest).Field1.GetStdValue())
+
+	// the Workbook builder calls BeforeExport too
+	w, err := NewWorkbook().AddSheet("钩子", []interface{}{
+		&hookTest{Field1: NewStringField("1"), Field2: NewIntField(1)},
+	}).Build()
+	assert.Nil(t, err)
+	cells, err := w.GetFile().GetRows("钩子")
+	assert.Nil(t, err)
+	assert.Equal(t, "#1", cells[2][0])
+
+	var scanned []string
+	f, err = NewExcelFromReader(bytes.NewReader(mustWriteBuffer(t, f)), HeaderRow(2), Use(
+		func(ctx *RowContext, next func() error) error {
+			scanned = append(scanned, ctx.Raw[0])
+			ctx.Raw[1] = strings.TrimSpace(ctx.Raw[1])
+			return next()
+		},
+		func(ctx *RowContext, next func() error) error {
+			if err := next(); err != nil {
+				return err
+			}
+			if ctx.Responses[0].(*hookTest).Field1.GetStdValue() == "1" {
+				return ctx.CellError("字段|字段1", errors.New("已存在"))
+			}
+			return nil
+		},
+	))
+	assert.Nil(t, err)
+
+	var errs []error
+	err = f.ScanSheetRows("Sheet1", func(rowIndex int, responses []interface{}, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		assert.Equal(t, "2-2", responses[0].(*hookTest).Label.GetStdValue())
+		return nil
+	}, new(hookTest))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"#1", "#2", "#3"}, scanned)
+	assert.Len(t, errs, 2)
+	var cellErr *CellError
+	assert.True(t, errors.As(errs[0], &cellErr))
+	assert.Equal(t, []int{3, 1}, []int{cellErr.RowIndex(), cellErr.ColIndex()})
+	assert.EqualError(t, cellErr.Unwrap(), "已存在")
+	assert.True(t, errors.As(errs[1], &cellErr))
+	assert.Equal(t, []int{5, 2}, []int{cellErr.RowIndex(), cellErr.ColIndex()})
+	assert.Equal(t, []string{"字段", "字段2"}, cellErr.Paths())
+
+	dataRows, err := f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	var valid int
+	for res := range f.AsyncScanRows(dataRows, new(hookTest)) {
+		if res.Err == nil {
+			valid++
+		}
+	}
+	assert.Equal(t, 1, valid)
+}
+
+func TestExcel_Progress(t *testing.T) {
+	var tests []interface{}
+	for i := 1; i <= 5; i++ {
+		tests = append(tests, &test{Field1: NewStringField(fmt.Sprintf("%d", i)), Field2: NewIntField(i)})
+	}
+
+	// only the beginnings and the ends are reported in a long interval
+	var reports []Progress
+	f, err := NewExcelFromData(tests, HeaderRow(2), OnProgress(func(p Progress) {
+		reports = append(reports, p)
+	}, time.Hour))
+	assert.Nil(t, err)
+	var buf bytes.Buffer
+	_, err = f.WriteTo(&buf)
+	assert.Nil(t, err)
+	assert.Equal(t, []Progress{
+		{Phase: PhaseWrite, Sheet: "Sheet1", Total: 5},
+		{Phase: PhaseWrite, Sheet: "Sheet1", Rows: 5, Total: 5, Done: true},
+		{Phase: PhaseSave},
+		{Phase: PhaseSave, Done: true},
+	}, reports)
+	data := buf.Bytes()
+
+	ch := make(chan Progress, 100)
+	f, err = NewExcelFromReader(bytes.NewReader(data), HeaderRow(2), ProgressChan(ch, time.Hour))
+	assert.Nil(t, err)
+	rows, err := f.GetRowsWithoutHeader()
+	assert.Nil(t, err)
+	for range f.AsyncScanRows(rows, new(test)) {
+	}
+	close(ch)
+	var phases []string
+	for p := range ch {
+		phases = append(phases, fmt.Sprintf("%s %s %d/%d %v", p.Phase, p.Sheet, p.Rows, p.Total, p.Done))
+	}
+	assert.Equal(t, []string{
+		"open  0/0 false",
+		"open  0/0 true",
+		"header Sheet1 0/0 false",
+		"header Sheet1 0/0 true",
+		"scan Sheet1 0/0 false",
+		"scan Sheet1 5/0 true",
+		"scan Sheet1 0/5 false",
+		"scan Sheet1 5/5 true",
+	}, phases)
+
+	// the open phase is reported by the other formats too
+	reports = nil
+	_, err = NewExcelFromCSV(strings.NewReader("a\n1\n"), OnProgress(func(p Progress) {
+		reports = append(reports, p)
+	}))
+	assert.Nil(t, err)
+	assert.Equal(t, []Progress{{Phase: PhaseOpen}, {Phase: PhaseOpen, Done: true}}, reports)
+
+	// the concurrent scans are reported separately
+	var (
+		mu    sync.Mutex
+		dones []Progress
+	)
+	f, err = NewExcelFromReader(bytes.NewReader(data), HeaderRow(2), OnProgress(func(p Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		if p.Phase == PhaseScan && p.Done {
+			dones = append(dones, p)
+		}
+	}))
+	assert.Nil(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range f.AsyncScanRows(rows, new(test)) {
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, []Progress{
+		{Phase: PhaseScan, Sheet: "Sheet1", Rows: 5, Total: 5, Done: true},
+		{Phase: PhaseScan, Sheet: "Sheet1", Rows: 5, Total: 5, Done: true},
+	}, dones)
+
+	// the rows are reported after the interval
+	slow := Use(func(ctx *RowContext, next func() error) error {
+		time.Sleep(5 * time.Millisecond)
+		return next()
+	})
+	reports = nil
+	f, err = NewExcelFromReader(bytes.NewReader(data), HeaderRow(2), slow, OnProgress(func(p Progress) {
+		reports = append(reports, p)
+	}, time.Millisecond))
+	assert.Nil(t, err)
+	assert.Nil(t, f.ScanSheetRows("Sheet1", func(int, []interface{}, error) error { return nil }, new(test)))
+	interim := 0
+	for _, p := range reports {
+		if p.Phase == PhaseScan && !p.Done && p.Rows > 0 {
+			interim++
+		}
+	}
+	assert.NotZero(t, interim)
+	assert.Equal(t, Progress{Phase: PhaseScan, Sheet: "Sheet1", Rows: 5, Done: true}, reports[len(reports)-1])
+
+	// the interim reports are dropped if the channel is full, the others are always sent
+	ch = make(chan Progress, 1)
+	received := make(chan []Progress)
+	go func() {
+		var reports []Progress
+		for p := range ch {
+			time.Sleep(10 * time.Millisecond)
+			reports = append(reports, p)
+		}
+		received <- reports
+	}()
+	f, err = NewExcelFromReader(bytes.NewReader(data), HeaderRow(2), slow, ProgressChan(ch, time.Millisecond))
+	assert.Nil(t, err)
+	assert.Nil(t, f.ScanSheetRows("Sheet1", func(int, []interface{}, error) error { return nil }, new(test)))
+	close(ch)
+	phases = nil
+	for _, p := range <-received {
+		if p.Done || p.Rows == 0 {
+			phases = append(phases, fmt.Sprintf("%s %s %d/%d %v", p.Phase, p.Sheet, p.Rows, p.Total, p.Done))
+		}
+	}
+	assert.Equal(t, []string{
+		"open  0/0 false",
+		"open  0/0 true",
+		"header Sheet1 0/0 false",
+		"header Sheet1 0/0 true",
+		"scan Sheet1 0/0 false",
+		"scan Sheet1 5/0 true",
+	}, phases)
+}
@@ -2,6 +2,7 @@ package excel
 
 import (
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/360EntSecGroup-Skylar/excelize/v2"
@@ -21,13 +22,20 @@ func (e *Excel) initFromData(rows []interface{}) (err error) {
 		err = errors.Wrap(err, "parseHeader")
 		return
 	}
-	_, err = e.writeHeader(header, 1, 0)
-	if err != nil {
-		err = errors.Wrap(err, "e.writeHeader")
-		return
+	dataRow := 1
+	if !e.noHeader {
+		sheets := make([]string, 0, len(e.sheetParts))
+		for _, part := range e.sheetParts {
+			sheets = append(sheets, part.name)
+		}
+		_, err = e.writeHeader(sheets, header, 1, 0)
+		if err != nil {
+			err = errors.Wrap(err, "e.writeHeader")
+			return
+		}
+		dataRow = header.getHeight()
 	}
-	dataRow := header.getHeight()
-	if err = e.writeData(rows, dataRow); err != nil {
+	if err = e.writeData(e.sheetParts, dataRow); err != nil {
 		err = errors.Wrap(err, "e.writeData")
 		return
 	}
@@ -35,7 +43,7 @@ func (e *Excel) initFromData(rows []interface{}) (err error) {
 	return
 }
 
-func (e *Excel) writeHeader(header *header, col, row int) (span int, err error) {
+func (e *Excel) writeHeader(sheets []string, header *header, col, row int) (span int, err error) {
 	if header == nil {
 		return
 	}
@@ -43,7 +51,7 @@ func (e *Excel) writeHeader(header *header, col, row int) (span int, err error)
 	var childrenSpan int
 	for _, child := range header.children {
 		var childSpan int
-		childSpan, err = e.writeHeader(child, col+childrenSpan, row+1)
+		childSpan, err = e.writeHeader(sheets, child, col+childrenSpan, row+1)
 		if err != nil {
 			err = errors.Wrap(err, "e.writeHeader")
 			return
@@ -69,14 +77,14 @@ func (e *Excel) writeHeader(header *header, col, row int) (span int, err error)
 				err = errors.Wrap(err, "excelize.CoordinatesToCellName")
 				return
 			}
-			for _, sheet := range e.activeSheetNames {
+			for _, sheet := range sheets {
 				err = e.ex.MergeCell(sheet, hCell, vCell)
 				if err != nil {
 					err = errors.Wrap(err, "e.ex.MergeCell")
 					return
 				}
 
-				err = e.ex.SetCellStyle(sheet, hCell, vCell, e.fieldStyleId)
+				err = e.ex.SetCellStyle(sheet, hCell, vCell, e.headerStyleId)
 				if err != nil {
 					err = errors.Wrap(err, "e.ex.SetCellStyle")
 					return
@@ -95,12 +103,18 @@ func (e *Excel) writeHeader(header *header, col, row int) (span int, err error)
 		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
 		return
 	}
-	for _, sheet := range e.activeSheetNames {
+	for _, sheet := range sheets {
 		err = e.ex.SetCellValue(sheet, axis, header.title)
 		if err != nil {
 			err = errors.Wrap(err, "e.ex.SetCellValue")
 			return
 		}
+
+		err = e.ex.SetCellStyle(sheet, axis, axis, e.headerStyleId)
+		if err != nil {
+			err = errors.Wrap(err, "e.ex.SetCellStyle")
+			return
+		}
 	}
 
 	return
@@ -114,25 +128,86 @@ type header struct {
 }
 
 func (h header) getHeight() (height int) {
-	if len(h.children) == 0 {
-		return 1
+	for _, child := range h.children {
+		if childHeight := child.getHeight(); childHeight > height {
+			height = childHeight
+		}
 	}
 
-	height = h.children[0].getHeight() + 1
+	height++
 	return
 }
 
+/**
+addPath add the headers of path which don't exist, the new headers are appended behind
+*/
+func (h *header) addPath(path []string) {
+	if len(path) == 0 {
+		return
+	}
+
+	var child *header
+	for _, c := range h.children {
+		if c.title == path[0] {
+			child = c
+			break
+		}
+	}
+	if child == nil {
+		child = &header{parent: h.title, title: path[0]}
+		h.children = append(h.children, child)
+	}
+	child.addPath(path[1:])
+}
+
+/**
+walkLeaves call fn with the path of every leaf header in order, the dummy root is not in the path
+*/
+func (h *header) walkLeaves(path []string, fn func(path []string)) {
+	if !h.isDummy {
+		path = append(path[:len(path):len(path)], h.title)
+	}
+	if len(h.children) == 0 {
+		if !h.isDummy {
+			fn(path)
+		}
+		return
+	}
+	for _, child := range h.children {
+		child.walkLeaves(path, fn)
+	}
+}
+
+/**
+parseHeader build the header from the tag paths of row, the fields with position tags are not in the header
+and their columns must be behind the header
+*/
 func parseHeader(row interface{}) (h *header, err error) {
 	h = &header{isDummy: true}
 
 	var paths [][]string
-	v := reflect.ValueOf(row).Elem()
-	for i := 0; i < reflect.Indirect(v).NumField(); i++ {
-		field := reflect.Indirect(v).Type().Field(i)
-		tag := field.Tag.Get(_tagFlag)
+	t := reflect.Indirect(reflect.ValueOf(row).Elem()).Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := t.Field(i).Tag.Get(_tagFlag)
+		if isColTag(tag) {
+			continue
+		}
 		path := strings.Split(tag, _tagPathSplitter)
 		paths = append(paths, path)
 	}
+	for i := 0; i < t.NumField(); i++ {
+		if tag := t.Field(i).Tag.Get(_tagFlag); isColTag(tag) {
+			var col int
+			if col, err = parseColTag(tag); err != nil {
+				err = errors.Wrapf(err, "field %s", t.Field(i).Name)
+				return
+			}
+			if col <= len(paths) {
+				err = errors.Errorf("col %d of field %s is in the header", col, t.Field(i).Name)
+				return
+			}
+		}
+	}
 	if len(paths) == 0 {
 		return
 	}
@@ -181,55 +256,261 @@ func getHeadersFromPaths(paths [][]string, colIdx, colMax int) (hs []*header) {
 	return hs
 }
 
-func (e *Excel) writeData(rows []interface{}, rowStart int) (err error) {
-	l := len(rows)
-	if l == 0 {
+func (e *Excel) writeData(parts []*sheetPart, rowStart int) (err error) {
+	for _, part := range parts {
+		if err = e.writeSheetData(part.name, part.rows, rowStart); err != nil {
+			err = errors.Wrap(err, "e.writeSheetData")
+			return
+		}
+	}
+
+	return
+}
+
+/**
+writeSheetData write the rows to sheet from rowStart, and the subtotals and the footer if needed
+*/
+func (e *Excel) writeSheetData(sheet string, rows []interface{}, rowStart int) (err error) {
+	var (
+		// the row index of each data row
+		rowIndices = make([]int, 0, len(rows))
+		nextRow    = rowStart
+	)
+	p := e.progress.start(PhaseWrite, sheet, len(rows))
+	defer p.finish()
+	if e.isGrouped() {
+		if nextRow, err = e.writeGroups(sheet, rows, 0, rowStart, &rowIndices, p); err != nil {
+			err = errors.Wrap(err, "e.writeGroups")
+			return
+		}
+	} else {
+		for _, row := range rows {
+			if err = e.writeRow(sheet, row, nextRow); err != nil {
+				err = errors.Wrap(err, "e.writeRow")
+				return
+			}
+			p.add(1)
+			rowIndices = append(rowIndices, nextRow)
+			nextRow++
+		}
+	}
+
+	if err = e.writeFooter(sheet, rows, rowStart, nextRow); err != nil {
+		err = errors.Wrap(err, "e.writeFooter")
 		return
 	}
 
-	if l < e.sheetCount {
-		err = errors.New("data rows is smaller than sheet count")
+	if err = e.mergeSameValues(sheet, rows, rowIndices); err != nil {
+		err = errors.Wrap(err, "e.mergeSameValues")
 		return
 	}
 
-	sheetRowSize := l / e.sheetCount
-	for idx := 0; idx < e.sheetCount; idx++ {
-		var sheetRows []interface{}
-		if idx == e.sheetCount-1 {
-			sheetRows = rows[idx*sheetRowSize:]
-		} else {
-			sheetRows = rows[idx*sheetRowSize : (idx+1)*sheetRowSize]
+	return
+}
+
+/**
+writeRow write the fields of row to the cells of rowIndex through the middlewares
+*/
+func (e *Excel) writeRow(sheet string, row interface{}, rowIndex int) (err error) {
+	if len(e.middlewares) == 0 {
+		return e.writeRowCells(sheet, row, rowIndex)
+	}
+
+	ctx := &RowContext{Sheet: sheet, RowIndex: rowIndex, Responses: []interface{}{row}, Export: true}
+	return e.runMiddlewares(ctx, func() error {
+		return e.writeRowCells(sheet, ctx.Responses[0], rowIndex)
+	})
+}
+
+/**
+writeRowCells write the fields of row to the cells of rowIndex
+*/
+func (e *Excel) writeRowCells(sheet string, row interface{}, rowIndex int) (err error) {
+	v := reflect.ValueOf(row).Elem()
+	for i := 0; i < reflect.Indirect(v).NumField(); i++ {
+		var axis string
+		axis, err = excelize.CoordinatesToCellName(fieldCol(reflect.Indirect(v).Type(), i), rowIndex)
+		if err != nil {
+			err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+			return
 		}
 
-		sheetRowStart := rowStart
-		sheet := e.activeSheetNames[idx]
-		for _, row := range sheetRows {
-			v := reflect.ValueOf(row).Elem()
-			for i := 0; i < reflect.Indirect(v).NumField(); i++ {
-				var axis string
-				axis, err = excelize.CoordinatesToCellName(i+1, sheetRowStart)
-				if err != nil {
-					err = errors.Wrap(err, "excelize.CoordinatesToCellName")
-					return
-				}
+		field := reflect.Indirect(v).Field(i)
+		if formulaField, ok := formulaOf(field); ok {
+			var formula string
+			formula, err = resolveFormula(formulaField.GetFormula(), reflect.Indirect(v).Type(), rowIndex)
+			if err != nil {
+				err = errors.Wrap(err, "resolveFormula")
+				return
+			}
+			if err = e.ex.SetCellFormula(sheet, axis, formula); err != nil {
+				err = errors.Wrap(err, "e.ex.SetCellFormula")
+				return
+			}
+			continue
+		}
 
-				var value interface{}
-				field := reflect.Indirect(v).Field(i)
-				if importField, ok := field.Interface().(Field); ok {
-					value = importField.GetValue()
-				} else {
-					value = field
-				}
-				err = e.ex.SetCellValue(sheet, axis, value)
-				if err != nil {
-					err = errors.Wrap(err, "e.ex.SetCellValue")
+		err = e.ex.SetCellValue(sheet, axis, exportValue(field))
+		if err != nil {
+			err = errors.Wrap(err, "e.ex.SetCellValue")
+			return
+		}
+	}
+
+	return
+}
+
+/**
+fieldCol return the col index of the field i of t when exporting, it begins with 1. The fields are in
+order except the fields with position tags, which are at their columns.
+*/
+func fieldCol(t reflect.Type, i int) int {
+	if tag := t.Field(i).Tag.Get(_tagFlag); isColTag(tag) {
+		col, _ := parseColTag(tag)
+		return col
+	}
+
+	col := 1
+	for j := 0; j < i; j++ {
+		if !isColTag(t.Field(j).Tag.Get(_tagFlag)) {
+			col++
+		}
+	}
+	return col
+}
+
+/**
+formulaOf return the FormulaField of the struct field, the unexported fields are not formulas
+*/
+func formulaOf(field reflect.Value) (formulaField FormulaField, ok bool) {
+	if !field.CanInterface() {
+		return
+	}
+	formulaField, ok = field.Interface().(FormulaField)
+	return
+}
+
+/**
+exportValue return the value written to the cell for a struct field
+*/
+func exportValue(field reflect.Value) interface{} {
+	if !field.CanInterface() {
+		return field
+	}
+	if importField, ok := field.Interface().(Field); ok {
+		return importField.GetValue()
+	}
+	return field.Interface()
+}
+
+/**
+fieldIndexByPath return the index of the struct field whose tag is path, a path can also be the behind part
+of a tag path, the same as ScanRow. -1 is returned if not found.
+*/
+func fieldIndexByPath(t reflect.Type, path string) int {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).Tag.Get(_tagFlag) == path {
+			return i
+		}
+	}
+
+	paths := strings.Split(path, _tagPathSplitter)
+	for i := 0; i < t.NumField(); i++ {
+		tagPaths := strings.Split(t.Field(i).Tag.Get(_tagFlag), _tagPathSplitter)
+		if len(tagPaths) >= len(paths) && reflect.DeepEqual(tagPaths[len(tagPaths)-len(paths):], paths) {
+			return i
+		}
+	}
+	return -1
+}
+
+var _formulaPathRegexp = regexp.MustCompile(`\{([^{}]+)\}`)
+
+/**
+resolveFormula replace the header paths in braces with the cells of the row
+*/
+func resolveFormula(formula string, t reflect.Type, row int) (res string, err error) {
+	res = _formulaPathRegexp.ReplaceAllStringFunc(strings.TrimPrefix(formula, "="), func(s string) string {
+		if err != nil {
+			return s
+		}
+
+		i := fieldIndexByPath(t, s[1:len(s)-1])
+		if i < 0 {
+			err = errors.Errorf("path %s in formula is not found in %s", s, t.Name())
+			return s
+		}
+
+		var axis string
+		if axis, err = excelize.CoordinatesToCellName(fieldCol(t, i), row); err != nil {
+			err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+		}
+		return axis
+	})
+	return
+}
+
+/**
+mergeSameValues merge the consecutive cells which have the same value in the columns of e.mergePaths,
+rowIndices are the row indices of rows
+*/
+func (e *Excel) mergeSameValues(sheet string, rows []interface{}, rowIndices []int) (err error) {
+	if len(e.mergePaths) == 0 || len(rows) == 0 {
+		return
+	}
+
+	t := reflect.Indirect(reflect.ValueOf(rows[0])).Type()
+	for _, path := range e.mergePaths {
+		fieldIndex := fieldIndexByPath(t, path)
+		if fieldIndex < 0 {
+			err = errors.Errorf("path %s is not found in %s", path, t.Name())
+			return
+		}
+
+		value := func(i int) interface{} {
+			return exportValue(reflect.Indirect(reflect.ValueOf(rows[i])).Field(fieldIndex))
+		}
+		groupStart := 0
+		for i := 1; i <= len(rows); i++ {
+			// the rows separated by a subtotal row are not merged
+			if i < len(rows) && rowIndices[i] == rowIndices[i-1]+1 && reflect.DeepEqual(value(i), value(groupStart)) {
+				continue
+			}
+			if i-groupStart > 1 {
+				if err = e.mergeCol(sheet, fieldCol(t, fieldIndex), rowIndices[groupStart], rowIndices[i-1]); err != nil {
 					return
 				}
 			}
-
-			sheetRowStart++
+			groupStart = i
 		}
 	}
 
 	return
 }
+
+/**
+mergeCol merge the cells of col from rowStart to rowEnd
+*/
+func (e *Excel) mergeCol(sheet string, col, rowStart, rowEnd int) (err error) {
+	hCell, err := excelize.CoordinatesToCellName(col, rowStart)
+	if err != nil {
+		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+		return
+	}
+	vCell, err := excelize.CoordinatesToCellName(col, rowEnd)
+	if err != nil {
+		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+		return
+	}
+
+	if err = e.ex.MergeCell(sheet, hCell, vCell); err != nil {
+		err = errors.Wrap(err, "e.ex.MergeCell")
+		return
+	}
+	if err = e.ex.SetCellStyle(sheet, hCell, vCell, e.fieldStyleId); err != nil {
+		err = errors.Wrap(err, "e.ex.SetCellStyle")
+		return
+	}
+
+	return
+}
@@ -243,3 +243,44 @@ func (bField *BoolField) SetValue(value bool) {
 func (bField BoolField) GetValue() interface{} {
 	return bField.value
 }
+
+/**
+FormulaField is a cell with formula. When exporting, the formula is written to the cell, and the header
+paths in braces are replaced by the cells of the same row, ex: "{订单|单价}*{订单|数量}"; when importing,
+the value is the calculated value of the cell.
+*/
+type FormulaField struct {
+	formula  string
+	value    string
+	colIndex int
+}
+
+func NewFormulaField(formula string) FormulaField {
+	return FormulaField{formula: formula}
+}
+
+var _ Field = (*FormulaField)(nil)
+
+func (fField FormulaField) Translate(value string, colIndex int) (interface{}, error) {
+	return FormulaField{formula: fField.formula, value: value, colIndex: colIndex}, nil
+}
+
+func (fField FormulaField) ColIndex() int {
+	return fField.colIndex
+}
+
+func (fField FormulaField) GetStdValue() string {
+	return fField.value
+}
+
+func (fField FormulaField) GetFormula() string {
+	return fField.formula
+}
+
+func (fField *FormulaField) SetFormula(formula string) {
+	fField.formula = formula
+}
+
+func (fField FormulaField) GetValue() interface{} {
+	return fField.value
+}
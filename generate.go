@@ -0,0 +1,147 @@
+package excel
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+)
+
+// errStopWalk stops walking the sheet rows once the samples are enough
+var errStopWalk = errors.New("stop walk")
+
+/**
+GenerateStruct generate the declaration of a struct named typeName for the header of sheet, every leaf
+header is a field with the tag of its path. The field types are guessed by the first data rows, the
+field names are the ASCII titles in camel case, or Col and the column letter for the others, ex: ColA.
+The types are qualified by the package name excel, the declaration is formatted.
+*/
+func (e *Excel) GenerateStruct(sheet, typeName string) (code []byte, err error) {
+	importer, err := e.importerOf(sheet)
+	if err != nil {
+		return
+	}
+	if len(importer.childImporters) == 0 {
+		err = errors.Errorf("sheet %s has no header", sheet)
+		return
+	}
+	leafNodes := importer.getLeafNodes()
+
+	samples := make([][]string, len(leafNodes))
+	err = e.WalkSheetRows(sheet, func(rowIndex int, row []string) error {
+		for i, leafNode := range leafNodes {
+			if col := leafNode.colIndexStart; col <= len(row) && strings.TrimSpace(row[col-1]) != "" {
+				samples[i] = append(samples[i], strings.TrimSpace(row[col-1]))
+			}
+		}
+		if rowIndex-e.getRowsBeginIndex(importer) >= _generateSampleRows {
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil && err != errStopWalk {
+		err = errors.Wrap(err, "e.WalkSheetRows")
+		return
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "type %s struct {\n", typeName)
+	names := make(map[string]bool, len(leafNodes))
+	for i, leafNode := range leafNodes {
+		colName, _ := excelize.ColumnNumberToName(leafNode.colIndexStart)
+		name := fieldName(leafNode.value, colName)
+		for n := 2; names[name]; n++ {
+			name = fmt.Sprintf("%s%d", fieldName(leafNode.value, colName), n)
+		}
+		names[name] = true
+
+		tag := fmt.Sprintf("%s:%q", _tagFlag, strings.Join(leafNode.path, _tagPathSplitter))
+		if strings.Contains(tag, "`") {
+			tag = strconv.Quote(tag)
+		} else {
+			tag = "`" + tag + "`"
+		}
+		axis, _ := excelize.CoordinatesToCellName(leafNode.colIndexStart, leafNode.rowIndexStart)
+		fmt.Fprintf(&buf, "\t%s excel.%s %s // %s\n", name, guessFieldType(samples[i]), tag, axis)
+	}
+	buf.WriteString("}\n")
+
+	if code, err = format.Source(buf.Bytes()); err != nil {
+		err = errors.Wrap(err, "format.Source")
+	}
+	return
+}
+
+/**
+fieldName return the exported name for the title, the words of an ASCII title are joined in camel case,
+Col and the column letter is used if the title has other characters or it's not a valid name
+*/
+func fieldName(title, colName string) string {
+	var (
+		buf   strings.Builder
+		upper = true
+	)
+	for _, r := range title {
+		switch {
+		case r > unicode.MaxASCII:
+			return "Col" + colName
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			buf.WriteRune(r)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+
+	name := buf.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		return "Col" + colName
+	}
+	return name
+}
+
+/**
+guessFieldType return the Field type which all the samples can be translated to, StringField by default
+*/
+func guessFieldType(samples []string) string {
+	if len(samples) == 0 {
+		return "StringField"
+	}
+
+	isInt, isInt64, isFloat, isBool, isTime := true, true, true, true, true
+	for _, sample := range samples {
+		v, err := strconv.ParseInt(sample, 10, 64)
+		isInt64 = isInt64 && err == nil
+		isInt = isInt && err == nil && v >= math.MinInt32 && v <= math.MaxInt32
+		_, err = strconv.ParseFloat(sample, 64)
+		isFloat = isFloat && err == nil
+		isBool = isBool && (sample == "是" || sample == "否")
+		_, err = time.Parse(_dateLayout, sample)
+		isTime = isTime && err == nil
+	}
+
+	switch {
+	case isInt:
+		return "IntField"
+	case isInt64:
+		return "Int64Field"
+	case isFloat:
+		return "FloatField"
+	case isBool:
+		return "BoolField"
+	case isTime:
+		return "TimeField"
+	default:
+		return "StringField"
+	}
+}
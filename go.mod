@@ -6,5 +6,8 @@ require (
 	github.com/360EntSecGroup-Skylar/excelize/v2 v2.3.2
 	github.com/panjf2000/ants/v2 v2.4.3
 	github.com/pkg/errors v0.9.1
+	github.com/richardlehane/mscfb v1.0.3
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/text v0.3.3
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
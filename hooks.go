@@ -0,0 +1,162 @@
+package excel
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+/**
+BeforeScanner is implemented by a response to check or normalize the raw cells before they are scanned,
+the changes of raw are scanned
+*/
+type BeforeScanner interface {
+	BeforeScan(raw []string) error
+}
+
+/**
+AfterScanner is implemented by a response to fill the other fields after the cells are scanned
+*/
+type AfterScanner interface {
+	AfterScan() error
+}
+
+/**
+Validator is implemented by a response to check the scanned fields, it's called after AfterScan.
+Return a CellError by NewCellError to locate the cell.
+*/
+type Validator interface {
+	Validate() error
+}
+
+/**
+BeforeExporter is implemented by a row to fill the fields before it's exported
+*/
+type BeforeExporter interface {
+	BeforeExport()
+}
+
+/**
+RowContext is the row passed through the middlewares
+*/
+type RowContext struct {
+	Sheet string
+	// RowIndex begins with 1, it's 0 if unknown
+	RowIndex int
+	// Raw is a copy of the cells when scanning, rewrite it before calling next to change the scanned values,
+	// it's nil when exporting
+	Raw []string
+	// Responses are the receivers when scanning, they are filled after calling next,
+	// it's the exported row when exporting
+	Responses []interface{}
+	// Export is true when exporting
+	Export bool
+
+	importer *Importer
+}
+
+/**
+Middleware is called for every scanned or exported row, call next to go on, and return an error to reject the row
+*/
+type Middleware func(ctx *RowContext, next func() error) error
+
+/**
+CellError return a CellError of the cell at the header path of the row, see NewCellError
+*/
+func (ctx *RowContext) CellError(path string, err error) error {
+	return withRowIndex(ctx.importer.locateCellError(NewCellError(path, err)), ctx.RowIndex)
+}
+
+/**
+locateCellError set the col index and the full path of the CellError in err which has only the header path
+*/
+func (root *Importer) locateCellError(err error) error {
+	var cellErr *CellError
+	if root == nil || !errors.As(err, &cellErr) || cellErr.colIndex != 0 || len(cellErr.paths) == 0 {
+		return err
+	}
+	for _, leafNode := range root.getLeafNodes() {
+		if len(leafNode.path) >= len(cellErr.paths) && reflect.DeepEqual(leafNode.path[len(leafNode.path)-len(cellErr.paths):], cellErr.paths) {
+			cellErr.colIndex, cellErr.paths = leafNode.colIndexStart, leafNode.path
+			break
+		}
+	}
+	return err
+}
+
+/**
+runMiddlewares call the middlewares of e in order, handler is called by the last next
+*/
+func (e *Excel) runMiddlewares(ctx *RowContext, handler func() error) error {
+	next := handler
+	for i := len(e.middlewares) - 1; i >= 0; i-- {
+		middleware, inner := e.middlewares[i], next
+		next = func() error {
+			return middleware(ctx, inner)
+		}
+	}
+	return next()
+}
+
+/**
+scanRow scan the row of importer through the middlewares
+*/
+func (e *Excel) scanRow(importer *Importer, rowIndex int, row []string, responses []interface{}) error {
+	if len(e.middlewares) == 0 {
+		return importer.ScanRow(row, responses...)
+	}
+
+	ctx := &RowContext{
+		Sheet:     importer.value,
+		RowIndex:  rowIndex,
+		Raw:       append([]string(nil), row...),
+		Responses: responses,
+		importer:  importer,
+	}
+	return e.runMiddlewares(ctx, func() error {
+		return importer.ScanRow(ctx.Raw, ctx.Responses...)
+	})
+}
+
+/**
+beforeScan call BeforeScan of the response with a copy of row, and return the changed row
+*/
+func beforeScan(resp interface{}, row []string) ([]string, error) {
+	scanner, ok := resp.(BeforeScanner)
+	if !ok {
+		return row, nil
+	}
+	raw := append([]string(nil), row...)
+	if err := scanner.BeforeScan(raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+/**
+afterScan call AfterScan and Validate of the response
+*/
+func afterScan(resp interface{}) error {
+	if scanner, ok := resp.(AfterScanner); ok {
+		if err := scanner.AfterScan(); err != nil {
+			return err
+		}
+	}
+	if validator, ok := resp.(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+beforeExport call BeforeExport of the rows, it's called once by every export before the rows are partitioned
+*/
+func beforeExport(rows []interface{}) {
+	for _, row := range rows {
+		if exporter, ok := row.(BeforeExporter); ok {
+			exporter.BeforeExport()
+		}
+	}
+}
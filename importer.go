@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 
@@ -28,6 +29,9 @@ type Importer struct {
 	path []string
 	// children nodes of current node
 	childImporters []*Importer
+
+	// bindings cache the bindings of the struct types, see Bind
+	bindings sync.Map
 }
 
 /**
@@ -44,6 +48,27 @@ func (root *Importer) GetRowIndexPos() (rowIndexStart, rowIndexEnd int) {
 	return root.rowIndexStart, root.rowIndexEnd
 }
 
+/**
+GetValue return the value of root cell, it's the sheet name for the root of a sheet
+*/
+func (root *Importer) GetValue() string {
+	return root.value
+}
+
+/**
+GetPath return the path from the sheet to root cell, it's nil for the root of a sheet
+*/
+func (root *Importer) GetPath() []string {
+	return root.path
+}
+
+/**
+GetChildren return the sub importers of root cell in col order
+*/
+func (root *Importer) GetChildren() []*Importer {
+	return root.childImporters
+}
+
 /**
 SubImporter return the sub importer by excel path
 */
@@ -88,7 +113,10 @@ func (root *Importer) subImporter(path []string) *Importer {
 ScanRow scan an excel row to structs. The func also support to scan a row by relative path
 ex: if a leaf node's path is `excel:"a|b|c"`, we can define a struct field `test` which has a tag `excel:"b|c"`, and
 it can scan because the path for the field match the leaf node's behind path
-Note: responses must be struct pointer types
+The cells are mapped by the columns of the leaf nodes, so the columns before, between and after the header are ignored,
+and a field with a position tag is mapped to the column directly, ex: `excel:"col=C"` or `excel:"#3"`
+The hooks BeforeScan, AfterScan and Validate of the responses are called if implemented.
+Note: responses must be struct pointer types, the fields are bound to the header once for each type, see Bind
 */
 func (root *Importer) ScanRow(row []string, responses ...interface{}) (err error) {
 	defer func() {
@@ -97,43 +125,55 @@ func (root *Importer) ScanRow(row []string, responses ...interface{}) (err error
 		}
 	}()
 
-	leafNodes := root.getLeafNodes()
-	leafNodesLength := len(leafNodes)
-	rowLength := len(row)
-	if leafNodesLength < rowLength {
-		row = row[rowLength-leafNodesLength:]
-	}
-	if rowLength < leafNodesLength {
-		for i := 0; i < leafNodesLength-rowLength; i++ {
-			row = append(row, "")
+	raw := row
+	for _, resp := range responses {
+		var v reflect.Value
+		if v, err = structOf(resp); err != nil {
+			return
+		}
+		var b *binding
+		if b, err = root.bind(v.Type()); err != nil {
+			return
+		}
+		if row, err = beforeScan(v.Addr().Interface(), raw); err != nil {
+			return root.locateCellError(err)
 		}
-	}
 
-	for _, resp := range responses {
-		v := reflect.ValueOf(resp).Elem()
-		for i := 0; i < reflect.Indirect(v).NumField(); i++ {
-			field := reflect.Indirect(v).Type().Field(i)
-			tag := field.Tag.Get(_tagFlag)
-			path := strings.Split(tag, _tagPathSplitter)
-			for j, leafNode := range leafNodes {
-				nodePath := leafNode.path[len(leafNode.path)-len(path):]
-				if reflect.DeepEqual(nodePath, path) {
-					var setValue interface{}
-					setValue, err = reflect.Indirect(v).Field(i).Interface().(Field).Translate(row[j], leafNode.colIndexStart)
-					if err != nil {
-						err = &CellError{rowIndex: j, colIndex: i, err: err}
-						return
-					}
-
-					reflect.Indirect(v).Field(i).Set(reflect.ValueOf(setValue))
-					break
-				}
+		for _, f := range b.fields {
+			var setValue interface{}
+			cellErr := f.cellError(nil)
+			setValue, err = v.Field(f.index).Interface().(Field).Translate(f.cellOf(row), cellErr.colIndex)
+			if err != nil {
+				cellErr.err = err
+				err = cellErr
+				return
+			}
+
+			value := reflect.ValueOf(setValue)
+			if !value.IsValid() || !value.Type().AssignableTo(v.Field(f.index).Type()) {
+				err = errors.Errorf("%T is translated to %T", v.Field(f.index).Interface(), setValue)
+				return
 			}
+			v.Field(f.index).Set(value)
+		}
+		if err = afterScan(v.Addr().Interface()); err != nil {
+			return root.locateCellError(err)
 		}
 	}
 	return
 }
 
+/**
+cellOf return the cell at the header column of the leaf node in row, the cells out of row are blank.
+The unlabelled columns covered by the leaf are not read, so a blank cell is blank.
+*/
+func (leafNode *Importer) cellOf(row []string) string {
+	if col := leafNode.colIndexStart; col >= 1 && col <= len(row) {
+		return row[col-1]
+	}
+	return ""
+}
+
 func (root *Importer) getLeafNodes() []*Importer {
 	if root == nil {
 		return nil
@@ -159,6 +199,23 @@ type AsyncScanExRes struct {
 AsyncScanRows scan rows to responses async
 */
 func (root *Importer) AsyncScanRows(rows [][]string, responses ...interface{}) chan *AsyncScanExRes {
+	return root.asyncScanRows(rows, root.ScanRow, responses...)
+}
+
+/**
+asyncScanRows scan rows to the new receivers of responses by scan async
+*/
+func (root *Importer) asyncScanRows(rows [][]string, scan func(row []string, responses ...interface{}) error, responses ...interface{}) chan *AsyncScanExRes {
+	for _, resp := range responses {
+		v, err := structOf(resp)
+		if err == nil {
+			err = root.Bind(v.Type())
+		}
+		if err != nil {
+			return asyncScanError(err)
+		}
+	}
+
 	pool, _ := ants.NewPool(runtime.NumCPU())
 
 	ch := make(chan *AsyncScanExRes, len(rows))
@@ -171,12 +228,8 @@ func (root *Importer) AsyncScanRows(rows [][]string, responses ...interface{}) c
 			defer wg.Done()
 
 			// we need to make a copy of the receiver for the row data
-			var respParams []interface{}
-			for _, resp := range responses {
-				respParams = append(respParams, reflect.New(reflect.Indirect(reflect.ValueOf(resp).Elem()).Type()).Interface())
-			}
-
-			err := root.ScanRow(rows[index], respParams...)
+			respParams := newResponses(responses)
+			err := scan(rows[index], respParams...)
 			ch <- &AsyncScanExRes{Responses: respParams, Err: err}
 		})
 	}
@@ -190,88 +243,49 @@ func (root *Importer) AsyncScanRows(rows [][]string, responses ...interface{}) c
 	return ch
 }
 
-func (root *Importer) IsHeaderConsistent(responses ...interface{}) (isConsistent bool, err error) {
-	defer func() {
-		if p := recover(); p != nil {
-			err = fmt.Errorf("internal error: %v", p)
-		}
-	}()
+/**
+asyncScanError return a closed channel which has only the error
+*/
+func asyncScanError(err error) chan *AsyncScanExRes {
+	ch := make(chan *AsyncScanExRes, 1)
+	ch <- &AsyncScanExRes{Err: err}
+	close(ch)
+	return ch
+}
 
-	leafNodes := root.getLeafNodes()
-	lastRespLength := 0
+/**
+newResponses make new receivers which have the same types as responses
+*/
+func newResponses(responses []interface{}) []interface{} {
+	respParams := make([]interface{}, 0, len(responses))
 	for _, resp := range responses {
-		t := reflect.TypeOf(resp)
-		switch t.Kind() {
-		case reflect.Ptr:
-			t = t.Elem()
-		default:
-			err = errors.New("response is not ptr type")
-			return
-		}
-
-		fieldNum := t.NumField()
-		for i := 0; i < fieldNum; i++ {
-			field := t.Field(i)
-			tag := field.Tag.Get(_tagFlag)
-			path := strings.Split(tag, _tagPathSplitter)
-
-			leafNode := leafNodes[lastRespLength+i]
-			if !reflect.DeepEqual(leafNode.path, path) {
-				return
-			}
-		}
-
-		lastRespLength += fieldNum
+		respParams = append(respParams, reflect.New(reflect.Indirect(reflect.ValueOf(resp).Elem()).Type()).Interface())
 	}
+	return respParams
+}
 
-	if lastRespLength != len(leafNodes) {
+/**
+IsHeaderConsistent return whether the sheet header matches the tag paths of responses exactly,
+use HeaderReport to know which column is wrong
+*/
+func (root *Importer) IsHeaderConsistent(responses ...interface{}) (isConsistent bool, err error) {
+	report, err := root.HeaderReport(responses...)
+	if err != nil {
+		err = errors.Wrap(err, "root.HeaderReport")
 		return
 	}
 
-	isConsistent = true
+	isConsistent = report.IsConsistent()
 	return
 }
 
 /**
-getChildrenColBoundary return start, end index
-start - the node's index in mergeCells which is root's first child
-end -  the node's index in mergeCells which is root's last child
+isChildOf return whether the node is under the root, it means the node is below the root and
+the col range of node is in the col range of root
 */
-func (root *Importer) getChildrenColBoundary(mergeCells []excelize.MergeCell) (start, end int, err error) {
-	var (
-		// the beginning col index of current cell
-		colIndexStart int
-		// the end col index of current cell
-		colIndexEnd int
-		// current cell's row index
-		rowIndex int
-	)
-
-	// set to negative to make sure it also work when there is no child.
-	end = -1
-
-	for i, cell := range mergeCells {
-		startAxis, endAxis := cell.GetStartAxis(), cell.GetEndAxis()
-		if colIndexStart, rowIndex, err = excelize.CellNameToCoordinates(startAxis); err != nil {
-			err = errors.Wrap(err, "excelize.CellNameToCoordinates")
-			return
-		}
-		if colIndexEnd, _, err = excelize.CellNameToCoordinates(endAxis); err != nil {
-			err = errors.Wrap(err, "excelize.CellNameToCoordinates")
-			return
-		}
-		if rowIndex > root.rowIndexEnd && colIndexStart >= root.colIndexStart && colIndexEnd <= root.colIndexEnd {
-			if colIndexStart == root.colIndexStart {
-				start = i
-			}
-			if colIndexEnd == root.colIndexEnd {
-				end = i
-				break
-			}
-		}
-	}
-
-	return
+func (node *Importer) isChildOf(root *Importer) bool {
+	return node.rowIndexStart > root.rowIndexEnd &&
+		node.colIndexStart >= root.colIndexStart && node.colIndexEnd <= root.colIndexEnd
 }
 
 /**
@@ -317,32 +331,53 @@ func buildChildNodes(root *Importer, mergeCells []excelize.MergeCell) (children
 		return
 	}
 
-	// get the root's first and last child in mergeCells
-	start, end, err := root.getChildrenColBoundary(mergeCells)
-	if err != nil {
-		err = errors.Wrap(err, "root.getChildrenColBoundary")
-		return
-	}
-	if end < 0 {
-		return
-	}
-
-	for i := start; i <= end; i++ {
-		node, e := buildNode(mergeCells[i])
+	nodes := make([]*Importer, 0, len(mergeCells))
+	for _, mergeCell := range mergeCells {
+		node, e := buildNode(mergeCell)
 		if e != nil {
 			err = errors.Wrap(e, "buildNodeByCell")
 			return
 		}
+		nodes = append(nodes, node)
+	}
 
-		// children's path
-		node.path = append(node.path, root.path...)
-		node.path = append(node.path, mergeCells[i].GetCellValue())
-		if node.childImporters, err = buildChildNodes(node, mergeCells); err != nil {
-			err = errors.Wrap(err, "buildChildNodes")
-			return
+	children = buildChildren(root, nodes)
+	return
+}
+
+/**
+buildChildren pick the children of root from nodes, the nodes under root which are not under
+any other node under root are its children, and they are sorted by col index
+*/
+func buildChildren(root *Importer, nodes []*Importer) (children []*Importer) {
+	var descendants []*Importer
+	for _, node := range nodes {
+		if node.isChildOf(root) {
+			descendants = append(descendants, node)
+		}
+	}
+
+	for _, node := range descendants {
+		isChild := true
+		for _, other := range descendants {
+			if other != node && node.isChildOf(other) {
+				isChild = false
+				break
+			}
+		}
+		if isChild {
+			children = append(children, node)
 		}
+	}
+	sort.SliceStable(children, func(i, j int) bool {
+		return children[i].colIndexStart < children[j].colIndexStart
+	})
 
-		children = append(children, node)
+	for _, node := range children {
+		// children's path
+		node.path = append(node.path, root.path...)
+		node.path = append(node.path, node.value)
+		node.childImporters = buildChildren(node, descendants)
 	}
 
 	return
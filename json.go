@@ -0,0 +1,454 @@
+package excel
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+)
+
+/**
+jsonObject is a JSON object whose keys are kept in order, the values are *jsonObject or the cell text
+*/
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+/**
+NewExcelFromJSON read a JSON array of objects to a sheet, the nested objects are written as the merged header
+groups in order of first appearance, ex: {"字段":{"字段1":"1"}} is the column 字段|字段1.
+The values are written as text, the arrays are written as JSON.
+*/
+func NewExcelFromJSON(reader io.Reader, options ...Option) (e *Excel, err error) {
+	e = newExcel()
+	for _, option := range options {
+		option(e)
+	}
+
+	p := e.progress.start(PhaseOpen, "", 0)
+	w := e.newJSONSheetWriter()
+	dec := json.NewDecoder(reader)
+	token, err := dec.Token()
+	if err != nil {
+		err = errors.Wrap(err, "dec.Token")
+		return
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		err = errors.New("JSON is not an array")
+		return
+	}
+	for dec.More() {
+		var object *jsonObject
+		if object, err = decodeJSONObject(dec); err != nil {
+			err = errors.Wrap(err, "decodeJSONObject")
+			return
+		}
+		if err = w.write(object); err != nil {
+			err = errors.Wrap(err, "w.write")
+			return
+		}
+	}
+
+	if err = w.finish(); err != nil {
+		err = errors.Wrap(err, "w.finish")
+		return
+	}
+	p.finish()
+	return
+}
+
+/**
+NewExcelFromNDJSON read the newline delimited JSON objects to a sheet, the same as NewExcelFromJSON.
+Every object is written to the sheet as soon as it is decoded, so only the sheet is held in memory.
+*/
+func NewExcelFromNDJSON(reader io.Reader, options ...Option) (e *Excel, err error) {
+	e = newExcel()
+	for _, option := range options {
+		option(e)
+	}
+
+	p := e.progress.start(PhaseOpen, "", 0)
+	w := e.newJSONSheetWriter()
+	dec := json.NewDecoder(reader)
+	for {
+		var object *jsonObject
+		if object, err = decodeJSONObject(dec); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			err = errors.Wrap(err, "decodeJSONObject")
+			return
+		}
+		if err = w.write(object); err != nil {
+			err = errors.Wrap(err, "w.write")
+			return
+		}
+	}
+
+	if err = w.finish(); err != nil {
+		err = errors.Wrap(err, "w.finish")
+		return
+	}
+	p.finish()
+	return
+}
+
+/**
+jsonSheetWriter write the JSON objects to a sheet one by one. The data rows are written from the first row
+while the header tree grows, the columns of the new keys are inserted among the written ones, and the header
+rows are inserted above the data at the end.
+*/
+type jsonSheetWriter struct {
+	e     *Excel
+	sheet string
+	root  *header
+	// valued is the leaves holding a value, they can't become groups
+	valued map[*header]bool
+	// grown is set when a key is added to the header tree
+	grown  bool
+	leaves []string
+	cols   map[string]int
+	row    int
+}
+
+func (e *Excel) newJSONSheetWriter() *jsonSheetWriter {
+	e.ex = excelize.NewFile()
+	sheet := fmt.Sprintf("%s%d", e.sheetPrefix, 1)
+	if sheet != _defaultSheetName {
+		e.ex.SetSheetName(_defaultSheetName, sheet)
+	}
+	return &jsonSheetWriter{
+		e:      e,
+		sheet:  sheet,
+		root:   &header{isDummy: true},
+		valued: make(map[*header]bool),
+		cols:   make(map[string]int),
+	}
+}
+
+func (w *jsonSheetWriter) write(object *jsonObject) (err error) {
+	if err = w.mergeHeader(w.root, object); err != nil {
+		err = errors.Wrap(err, "w.mergeHeader")
+		return
+	}
+	if w.grown {
+		if err = w.layoutColumns(); err != nil {
+			err = errors.Wrap(err, "w.layoutColumns")
+			return
+		}
+		w.grown = false
+	}
+
+	w.row++
+	if err = w.e.writeJSONObject(w.sheet, object, nil, w.cols, w.row); err != nil {
+		err = errors.Wrap(err, "w.e.writeJSONObject")
+	}
+	return
+}
+
+/**
+mergeHeader add the keys of object to the header tree, the new keys are appended behind. A leaf which only
+had null values becomes a group if an object comes later.
+*/
+func (w *jsonSheetWriter) mergeHeader(h *header, object *jsonObject) (err error) {
+	for _, key := range object.keys {
+		var child *header
+		for _, c := range h.children {
+			if c.title == key {
+				child = c
+				break
+			}
+		}
+		if child == nil {
+			child = &header{parent: h.title, title: key}
+			h.children = append(h.children, child)
+			w.grown = true
+		}
+
+		value := object.values[key]
+		if value == nil {
+			continue
+		}
+		childObject, isObject := value.(*jsonObject)
+		if isObject && w.valued[child] || !isObject && len(child.children) != 0 {
+			err = errors.Errorf("key %s is both an object and a value", key)
+			return
+		}
+		if isObject {
+			if err = w.mergeHeader(child, childObject); err != nil {
+				return
+			}
+		} else {
+			w.valued[child] = true
+		}
+	}
+	return
+}
+
+/**
+layoutColumns move the written columns to the leaf order of the header tree. The leaves only keep their order
+when the tree grows, so the new leaves are inserted as columns, and the column of a leaf which became a group
+is empty and taken by its first child.
+*/
+func (w *jsonSheetWriter) layoutColumns() (err error) {
+	var leaves []string
+	w.root.walkLeaves(nil, func(path []string) {
+		leaves = append(leaves, strings.Join(path, _tagPathSplitter))
+	})
+	isLeaf := make(map[string]bool, len(leaves))
+	for _, leaf := range leaves {
+		isLeaf[leaf] = true
+	}
+
+	for i, leaf := range leaves {
+		if i < len(w.leaves) && w.leaves[i] == leaf {
+			continue
+		}
+		if i < len(w.leaves) && !isLeaf[w.leaves[i]] {
+			w.leaves[i] = leaf
+			continue
+		}
+		if i < len(w.leaves) {
+			var column string
+			if column, err = excelize.ColumnNumberToName(i + 1); err != nil {
+				err = errors.Wrap(err, "excelize.ColumnNumberToName")
+				return
+			}
+			if err = w.e.ex.InsertCol(w.sheet, column); err != nil {
+				err = errors.Wrap(err, "w.e.ex.InsertCol")
+				return
+			}
+		}
+		w.leaves = append(w.leaves[:i], append([]string{leaf}, w.leaves[i:]...)...)
+	}
+
+	for i, leaf := range w.leaves {
+		w.cols[leaf] = i + 1
+	}
+	return
+}
+
+/**
+finish insert the header rows above the data rows and initialize the excel
+*/
+func (w *jsonSheetWriter) finish() (err error) {
+	e := w.e
+	height := w.root.getHeight()
+	if e.headerRow == 0 && e.headerRange == "" && len(e.headerTemplates) == 0 {
+		// the leaf headers are not merged
+		e.headerRow = height - 1
+	}
+
+	initData := func([]interface{}) (err error) {
+		if w.row > 0 {
+			for i := 1; i < height; i++ {
+				if err = e.ex.InsertRow(w.sheet, 1); err != nil {
+					err = errors.Wrap(err, "e.ex.InsertRow")
+					return
+				}
+			}
+		}
+		if _, err = e.writeHeader([]string{w.sheet}, w.root, 1, 0); err != nil {
+			err = errors.Wrap(err, "e.writeHeader")
+		}
+		return
+	}
+	if err = e.postInitialize(nil, initData); err != nil {
+		err = errors.Wrapf(err, "s.postInitialize")
+	}
+	return
+}
+
+/**
+decodeJSONObject decode the next JSON value which must be an object, io.EOF is returned at the end
+*/
+func decodeJSONObject(dec *json.Decoder) (object *jsonObject, err error) {
+	var raw json.RawMessage
+	if err = dec.Decode(&raw); err != nil {
+		return
+	}
+	value, err := parseJSONValue(raw)
+	if err != nil {
+		return
+	}
+
+	object, ok := value.(*jsonObject)
+	if !ok {
+		err = errors.Errorf("%s is not an object", raw)
+	}
+	return
+}
+
+func parseJSONValue(raw json.RawMessage) (value interface{}, err error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return
+	}
+
+	switch raw[0] {
+	case '{':
+		object := &jsonObject{values: make(map[string]interface{})}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		if _, err = dec.Token(); err != nil {
+			return
+		}
+		for dec.More() {
+			var token json.Token
+			if token, err = dec.Token(); err != nil {
+				return
+			}
+			key, _ := token.(string)
+			var child json.RawMessage
+			if err = dec.Decode(&child); err != nil {
+				return
+			}
+			if _, ok := object.values[key]; !ok {
+				object.keys = append(object.keys, key)
+			}
+			if object.values[key], err = parseJSONValue(child); err != nil {
+				return
+			}
+		}
+		value = object
+	case '"':
+		var s string
+		err = json.Unmarshal(raw, &s)
+		value = s
+	case 'n':
+	default:
+		// numbers, booleans and arrays
+		var buf bytes.Buffer
+		err = json.Compact(&buf, raw)
+		value = buf.String()
+	}
+	return
+}
+
+/**
+writeJSONObject write the values of object to row, cols is the column of every leaf path
+*/
+func (e *Excel) writeJSONObject(sheet string, object *jsonObject, path []string, cols map[string]int, row int) (err error) {
+	for _, key := range object.keys {
+		keyPath := append(path[:len(path):len(path)], key)
+		switch value := object.values[key].(type) {
+		case *jsonObject:
+			if err = e.writeJSONObject(sheet, value, keyPath, cols, row); err != nil {
+				return
+			}
+		case string:
+			if value == "" {
+				continue
+			}
+			var axis string
+			if axis, err = excelize.CoordinatesToCellName(cols[strings.Join(keyPath, _tagPathSplitter)], row); err != nil {
+				err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+				return
+			}
+			if err = e.ex.SetCellStr(sheet, axis, value); err != nil {
+				err = errors.Wrap(err, "e.ex.SetCellStr")
+				return
+			}
+		}
+	}
+	return
+}
+
+/**
+WriteJSON write the data rows of sheet to w as a JSON array, every row is an object keyed by the header
+tree, ex: {"字段":{"字段1":"1"}}. The first active sheet is written if sheet is empty.
+*/
+func (e *Excel) WriteJSON(w io.Writer, sheet string) (err error) {
+	bw := bufio.NewWriter(w)
+	_, _ = bw.WriteString("[")
+	first := true
+	err = e.walkJSONRows(sheet, func(object []byte) error {
+		if !first {
+			_, _ = bw.WriteString(",")
+		}
+		first = false
+		_, _ = bw.WriteString("\n")
+		_, err := bw.Write(object)
+		return err
+	})
+	if err != nil {
+		err = errors.Wrap(err, "e.walkJSONRows")
+		return
+	}
+	_, _ = bw.WriteString("\n]\n")
+	if err = bw.Flush(); err != nil {
+		err = errors.Wrap(err, "bw.Flush")
+	}
+	return
+}
+
+/**
+WriteNDJSON write the data rows of sheet to w as newline delimited JSON, one object per line, the rows
+are written one by one so that it fits the large sheets
+*/
+func (e *Excel) WriteNDJSON(w io.Writer, sheet string) (err error) {
+	bw := bufio.NewWriter(w)
+	err = e.walkJSONRows(sheet, func(object []byte) error {
+		if _, err := bw.Write(object); err != nil {
+			return err
+		}
+		return bw.WriteByte('\n')
+	})
+	if err != nil {
+		err = errors.Wrap(err, "e.walkJSONRows")
+		return
+	}
+	if err = bw.Flush(); err != nil {
+		err = errors.Wrap(err, "bw.Flush")
+	}
+	return
+}
+
+func (e *Excel) walkJSONRows(sheet string, fn func(object []byte) error) error {
+	if sheet == "" {
+		sheet = e.activeSheetNames[_defaultSheetIndex]
+	}
+	importer, err := e.importerOf(sheet)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	return e.WalkSheetRows(sheet, func(rowIndex int, row []string) error {
+		buf.Reset()
+		writeJSONRow(&buf, importer, row)
+		return fn(buf.Bytes())
+	})
+}
+
+/**
+writeJSONRow write the row as an object whose keys are the children of node, the leaf nodes are the cells
+*/
+func writeJSONRow(buf *bytes.Buffer, node *Importer, row []string) {
+	buf.WriteByte('{')
+	for i, child := range node.childImporters {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(child.value)
+		buf.Write(key)
+		buf.WriteByte(':')
+
+		if len(child.childImporters) != 0 {
+			writeJSONRow(buf, child, row)
+			continue
+		}
+		var value string
+		if child.colIndexStart >= 1 && child.colIndexStart <= len(row) {
+			value = row[child.colIndexStart-1]
+		}
+		s, _ := json.Marshal(value)
+		buf.Write(s)
+	}
+	buf.WriteByte('}')
+}
@@ -0,0 +1,88 @@
+package excel
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+)
+
+/**
+loadedSheet is a sheet read from the other spreadsheet formats, the indices are 0-based
+*/
+type loadedSheet struct {
+	name   string
+	cells  []loadedCell
+	merges []loadedMerge
+}
+
+type loadedCell struct {
+	row, col int
+	value    string
+}
+
+type loadedMerge struct {
+	rowStart, rowEnd, colStart, colEnd int
+}
+
+/**
+loadSheets write the loaded sheets to the xlsx workbook as strings, the default sheet is renamed to the first sheet
+*/
+func loadSheets(ex *excelize.File, sheets []*loadedSheet) (err error) {
+	if len(sheets) == 0 {
+		err = errors.New("no sheet exist")
+		return
+	}
+
+	for i, sheet := range sheets {
+		if i == 0 {
+			ex.SetSheetName(_defaultSheetName, sheet.name)
+		} else {
+			ex.NewSheet(sheet.name)
+		}
+
+		for _, cell := range sheet.cells {
+			if cell.value == "" {
+				continue
+			}
+			var axis string
+			if axis, err = excelize.CoordinatesToCellName(cell.col+1, cell.row+1); err != nil {
+				err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+				return
+			}
+			if err = ex.SetCellStr(sheet.name, axis, cell.value); err != nil {
+				err = errors.Wrap(err, "ex.SetCellStr")
+				return
+			}
+		}
+
+		for _, merge := range sheet.merges {
+			var hCell, vCell string
+			if hCell, err = excelize.CoordinatesToCellName(merge.colStart+1, merge.rowStart+1); err != nil {
+				err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+				return
+			}
+			if vCell, err = excelize.CoordinatesToCellName(merge.colEnd+1, merge.rowEnd+1); err != nil {
+				err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+				return
+			}
+			if err = ex.MergeCell(sheet.name, hCell, vCell); err != nil {
+				err = errors.Wrap(err, "ex.MergeCell")
+				return
+			}
+		}
+	}
+
+	return
+}
+
+/**
+formatFloat format the number as the shortest decimal, the integers are formatted without the exponent
+*/
+func formatFloat(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
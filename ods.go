@@ -0,0 +1,469 @@
+package excel
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	_odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+	_odsContent  = "content.xml"
+	_odsOfficeNS = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	_odsTableNS  = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	_odsTextNS   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	_odsDateTime = "2006-01-02T15:04:05.999999999"
+	_odsManifest = `<?xml version="1.0" encoding="UTF-8"?>
+<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">
+ <manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="application/vnd.oasis.opendocument.spreadsheet"/>
+ <manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>
+</manifest:manifest>`
+)
+
+/**
+NewExcelFromODS read an OpenDocument spreadsheet, the cells and the merged cells are loaded to a xlsx
+workbook in memory, so the header is built and the rows are scanned the same as a xlsx file.
+The numbers are read as the stored values, and the dates are formatted as "2006-01-02".
+*/
+func NewExcelFromODS(reader io.Reader, options ...Option) (e *Excel, err error) {
+	e = newExcel()
+	for _, option := range options {
+		option(e)
+	}
+
+	p := e.progress.start(PhaseOpen, "", 0)
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		err = errors.Wrap(err, "ioutil.ReadAll")
+		return
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		err = errors.Wrap(err, "zip.NewReader")
+		return
+	}
+
+	var sheets []*loadedSheet
+	for _, file := range zr.File {
+		if file.Name != _odsContent {
+			continue
+		}
+		var rc io.ReadCloser
+		if rc, err = file.Open(); err != nil {
+			err = errors.Wrap(err, "file.Open")
+			return
+		}
+		sheets, err = readODSContent(rc)
+		_ = rc.Close()
+		if err != nil {
+			err = errors.Wrap(err, "readODSContent")
+			return
+		}
+	}
+
+	e.ex = excelize.NewFile()
+	if err = loadSheets(e.ex, sheets); err != nil {
+		err = errors.Wrap(err, "loadSheets")
+		return
+	}
+
+	if err = e.postInitialize(nil, nil); err != nil {
+		err = errors.Wrapf(err, "s.postInitialize")
+		return
+	}
+	p.finish()
+
+	return
+}
+
+/**
+odsCell is the table cell being read, a repeated cell is read once and copied
+*/
+type odsCell struct {
+	valueType, value     string
+	text                 strings.Builder
+	paragraphs           int
+	colRepeat            int
+	colSpan, rowSpan     int
+	inParagraph, covered bool
+}
+
+/**
+readODSContent read the tables in content.xml, the repeated rows and cells are expanded only if they have values
+*/
+func readODSContent(reader io.Reader) (sheets []*loadedSheet, err error) {
+	var (
+		dec       = xml.NewDecoder(reader)
+		sheet     *loadedSheet
+		cell      *odsCell
+		row, col  int
+		rowRepeat int
+		// the index of the first cell of the current row in sheet.cells
+		rowCellStart int
+		// the depth of the comments whose paragraphs are not the cell text
+		annotation int
+	)
+	for {
+		var token xml.Token
+		if token, err = dec.Token(); err == io.EOF {
+			err = nil
+			break
+		} else if err != nil {
+			err = errors.Wrap(err, "dec.Token")
+			return
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == _odsTableNS && t.Name.Local == "table":
+				sheet = &loadedSheet{name: odsAttr(t, _odsTableNS, "name")}
+				sheets = append(sheets, sheet)
+				row = 0
+			case sheet == nil:
+			case t.Name.Space == _odsTableNS && t.Name.Local == "table-row":
+				col, rowRepeat, rowCellStart = 0, odsIntAttr(t, "number-rows-repeated"), len(sheet.cells)
+			case t.Name.Space == _odsTableNS && (t.Name.Local == "table-cell" || t.Name.Local == "covered-table-cell"):
+				cell = &odsCell{
+					valueType: odsAttr(t, _odsOfficeNS, "value-type"),
+					colRepeat: odsIntAttr(t, "number-columns-repeated"),
+					colSpan:   odsIntAttr(t, "number-columns-spanned"),
+					rowSpan:   odsIntAttr(t, "number-rows-spanned"),
+					covered:   t.Name.Local == "covered-table-cell",
+				}
+				switch cell.valueType {
+				case "float", "percentage", "currency":
+					cell.value = odsAttr(t, _odsOfficeNS, "value")
+				case "date":
+					cell.value = odsAttr(t, _odsOfficeNS, "date-value")
+				case "boolean":
+					cell.value = odsAttr(t, _odsOfficeNS, "boolean-value")
+				}
+			case t.Name.Space == _odsOfficeNS && t.Name.Local == "annotation":
+				annotation++
+			case cell == nil || annotation > 0 || t.Name.Space != _odsTextNS:
+			case t.Name.Local == "p" || t.Name.Local == "h":
+				if cell.paragraphs > 0 {
+					cell.text.WriteString("\n")
+				}
+				cell.paragraphs++
+				cell.inParagraph = true
+			case t.Name.Local == "s":
+				cell.text.WriteString(strings.Repeat(" ", odsIntAttr(t, "c")))
+			case t.Name.Local == "tab":
+				cell.text.WriteString("\t")
+			case t.Name.Local == "line-break":
+				cell.text.WriteString("\n")
+			}
+		case xml.CharData:
+			if cell != nil && cell.inParagraph && annotation == 0 {
+				cell.text.Write(t)
+			}
+		case xml.EndElement:
+			switch {
+			case sheet == nil:
+			case t.Name.Space == _odsOfficeNS && t.Name.Local == "annotation":
+				annotation--
+			case t.Name.Space == _odsTableNS && t.Name.Local == "table":
+				sheet = nil
+			case t.Name.Space == _odsTableNS && t.Name.Local == "table-row":
+				// a repeated row with values is copied
+				cells := sheet.cells[rowCellStart:]
+				for i := 1; i < rowRepeat && len(cells) != 0; i++ {
+					for _, c := range cells {
+						sheet.cells = append(sheet.cells, loadedCell{row: row + i, col: c.col, value: c.value})
+					}
+				}
+				row += rowRepeat
+			case t.Name.Space == _odsTableNS && (t.Name.Local == "table-cell" || t.Name.Local == "covered-table-cell"):
+				if cell == nil {
+					break
+				}
+				var value string
+				if !cell.covered {
+					if value, err = cell.format(); err != nil {
+						err = errors.Wrapf(err, "cell.format %s", sheet.name)
+						return
+					}
+				}
+				for i := 0; i < cell.colRepeat; i++ {
+					if value != "" {
+						sheet.cells = append(sheet.cells, loadedCell{row: row, col: col + i, value: value})
+					}
+					if cell.colSpan > 1 || cell.rowSpan > 1 {
+						sheet.merges = append(sheet.merges, loadedMerge{
+							rowStart: row, rowEnd: row + cell.rowSpan - 1,
+							colStart: col + i, colEnd: col + i + cell.colSpan - 1,
+						})
+					}
+				}
+				col += cell.colRepeat
+				cell = nil
+			case cell != nil && t.Name.Space == _odsTextNS && (t.Name.Local == "p" || t.Name.Local == "h"):
+				cell.inParagraph = false
+			}
+		}
+	}
+
+	if len(sheets) == 0 {
+		err = errors.New("no table exist, it's not an ods spreadsheet")
+	}
+	return
+}
+
+/**
+format return the cell value as text, the same as the other formats are read
+*/
+func (c *odsCell) format() (value string, err error) {
+	switch c.valueType {
+	case "float", "percentage", "currency":
+		var v float64
+		if v, err = strconv.ParseFloat(c.value, 64); err != nil {
+			err = errors.Wrap(err, "strconv.ParseFloat")
+			return
+		}
+		value = formatFloat(v)
+	case "date":
+		var t time.Time
+		if t, err = parseODSDate(c.value); err != nil {
+			err = errors.Wrap(err, "parseODSDate")
+			return
+		}
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+			value = t.Format(_dateLayout)
+		} else {
+			value = t.Format(_dateLayout + " 15:04:05")
+		}
+	case "boolean":
+		value = strings.ToUpper(c.value)
+	default:
+		value = c.text.String()
+	}
+	return
+}
+
+/**
+parseODSDate parse the office:date-value, it's a date, or a date time with the optional fractional seconds and zone
+*/
+func parseODSDate(value string) (t time.Time, err error) {
+	for _, layout := range []string{time.RFC3339Nano, _odsDateTime, _dateLayout} {
+		if t, err = time.Parse(layout, value); err == nil {
+			return
+		}
+	}
+	return
+}
+
+func odsAttr(t xml.StartElement, space, local string) string {
+	for _, attr := range t.Attr {
+		if attr.Name.Space == space && attr.Name.Local == local {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+/**
+odsIntAttr return the integer attribute of the table and text namespace, 1 is returned if not exist
+*/
+func odsIntAttr(t xml.StartElement, local string) int {
+	for _, attr := range t.Attr {
+		if (attr.Name.Space == _odsTableNS || attr.Name.Space == _odsTextNS) && attr.Name.Local == local {
+			if n, err := strconv.Atoi(attr.Value); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return 1
+}
+
+/**
+WriteODS write all the sheets to w as an OpenDocument spreadsheet, the merged cells are kept so that the
+header is the same. The cells which are decimal numbers are written as float, the others as string.
+*/
+func (e *Excel) WriteODS(w io.Writer) (err error) {
+	var content bytes.Buffer
+	content.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` +
+		`<office:document-content xmlns:office="` + _odsOfficeNS + `" xmlns:table="` + _odsTableNS +
+		`" xmlns:text="` + _odsTextNS + `" office:version="1.2"><office:body><office:spreadsheet>`)
+	for _, sheet := range e.ex.GetSheetList() {
+		if err = e.writeODSTable(&content, sheet); err != nil {
+			err = errors.Wrap(err, "e.writeODSTable")
+			return
+		}
+	}
+	content.WriteString(`</office:spreadsheet></office:body></office:document-content>`)
+
+	zw := zip.NewWriter(w)
+	// the mimetype must be the first file and not compressed
+	files := []struct {
+		name   string
+		method uint16
+		data   []byte
+	}{
+		{"mimetype", zip.Store, []byte(_odsMimeType)},
+		{"META-INF/manifest.xml", zip.Deflate, []byte(_odsManifest)},
+		{_odsContent, zip.Deflate, content.Bytes()},
+	}
+	for _, file := range files {
+		var fw io.Writer
+		if fw, err = zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: file.method}); err != nil {
+			err = errors.Wrap(err, "zw.CreateHeader")
+			return
+		}
+		if _, err = fw.Write(file.data); err != nil {
+			err = errors.Wrap(err, "fw.Write")
+			return
+		}
+	}
+	if err = zw.Close(); err != nil {
+		err = errors.Wrap(err, "zw.Close")
+	}
+	return
+}
+
+func (e *Excel) writeODSTable(buf *bytes.Buffer, sheet string) (err error) {
+	if err = e.checkReadable(); err != nil {
+		return
+	}
+	rows, err := e.ex.GetRows(sheet)
+	if err != nil {
+		err = errors.Wrap(err, "e.ex.GetRows")
+		return
+	}
+	mergeCells, err := e.ex.GetMergeCells(sheet)
+	if err != nil {
+		err = errors.Wrap(err, "e.ex.GetMergeCells")
+		return
+	}
+
+	// the merged cells are keyed by the 1-based coordinates of the top left cell
+	type span struct{ cols, rows int }
+	spans := make(map[[2]int]span, len(mergeCells))
+	covered := make(map[[2]int]bool)
+	width, height := 0, len(rows)
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	for _, mergeCell := range mergeCells {
+		var startCol, startRow, endCol, endRow int
+		if startCol, startRow, err = excelize.CellNameToCoordinates(mergeCell.GetStartAxis()); err != nil {
+			err = errors.Wrap(err, "excelize.CellNameToCoordinates")
+			return
+		}
+		if endCol, endRow, err = excelize.CellNameToCoordinates(mergeCell.GetEndAxis()); err != nil {
+			err = errors.Wrap(err, "excelize.CellNameToCoordinates")
+			return
+		}
+		spans[[2]int{startCol, startRow}] = span{cols: endCol - startCol + 1, rows: endRow - startRow + 1}
+		for r := startRow; r <= endRow; r++ {
+			for c := startCol; c <= endCol; c++ {
+				if r != startRow || c != startCol {
+					covered[[2]int{c, r}] = true
+				}
+			}
+		}
+		if endCol > width {
+			width = endCol
+		}
+		if endRow > height {
+			height = endRow
+		}
+	}
+	if width == 0 {
+		width = 1
+	}
+
+	buf.WriteString(`<table:table table:name="`)
+	_ = xml.EscapeText(buf, []byte(sheet))
+	fmt.Fprintf(buf, `"><table:table-column table:number-columns-repeated="%d"/>`, width)
+	for r := 1; r <= height; r++ {
+		buf.WriteString(`<table:table-row>`)
+		for c := 1; c <= width; c++ {
+			if covered[[2]int{c, r}] {
+				buf.WriteString(`<table:covered-table-cell/>`)
+				continue
+			}
+
+			var value string
+			if r <= len(rows) && c <= len(rows[r-1]) {
+				value = rows[r-1][c-1]
+			}
+			buf.WriteString(`<table:table-cell`)
+			if s, ok := spans[[2]int{c, r}]; ok {
+				fmt.Fprintf(buf, ` table:number-columns-spanned="%d" table:number-rows-spanned="%d"`, s.cols, s.rows)
+			}
+			if value == "" {
+				buf.WriteString(`/>`)
+				continue
+			}
+			if v, e := strconv.ParseFloat(value, 64); e == nil && strconv.FormatFloat(v, 'f', -1, 64) == value {
+				fmt.Fprintf(buf, ` office:value-type="float" office:value="%s">`, value)
+			} else {
+				buf.WriteString(` office:value-type="string">`)
+			}
+			for _, line := range strings.Split(value, "\n") {
+				buf.WriteString(`<text:p>`)
+				writeODSText(buf, line)
+				buf.WriteString(`</text:p>`)
+			}
+			buf.WriteString(`</table:table-cell>`)
+		}
+		buf.WriteString(`</table:table-row>`)
+	}
+	buf.WriteString(`</table:table>`)
+
+	return
+}
+
+/**
+writeODSText write the escaped text of a paragraph, the leading and consecutive spaces and the tabs are
+written as elements, otherwise they are collapsed
+*/
+func writeODSText(buf *bytes.Buffer, text string) {
+	var (
+		spaces int
+		// a single space is kept only if it follows a character
+		afterChar bool
+	)
+	flush := func() {
+		if spaces == 0 {
+			return
+		}
+		if afterChar {
+			buf.WriteByte(' ')
+			spaces--
+		}
+		if spaces > 0 {
+			fmt.Fprintf(buf, `<text:s text:c="%d"/>`, spaces)
+		}
+		spaces = 0
+	}
+
+	for _, r := range text {
+		switch r {
+		case ' ':
+			spaces++
+		case '\t':
+			flush()
+			buf.WriteString(`<text:tab/>`)
+			afterChar = false
+		default:
+			flush()
+			_ = xml.EscapeText(buf, []byte(string(r)))
+			afterChar = true
+		}
+	}
+	flush()
+}
@@ -1,7 +1,19 @@
 package excel
 
+import (
+	"strings"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+)
+
 type Option func(*Excel)
 
+/**
+RowFilter report whether the rule matches the row
+*/
+type RowFilter func(row []string) bool
+
 /**
 OpenPassword set the excel open password
 */
@@ -46,3 +58,345 @@ func HeaderRow(headerRow int) Option {
 		e.headerRow = headerRow
 	}
 }
+
+/**
+SpecHeader set the header row to the HeaderRow of spec if it's set and the header is not set by the options before
+*/
+func SpecHeader(spec *Spec) Option {
+	return func(e *Excel) {
+		if spec.HeaderRow != 0 && e.headerRow == 0 && e.headerRange == "" && len(e.headerTemplates) == 0 {
+			e.headerRow = spec.HeaderRow
+		}
+	}
+}
+
+/**
+HeaderRange set the cell range of the header, ex: "B3:H4", for the sheets which
+have a title banner or a leading blank column
+*/
+func HeaderRange(ref string) Option {
+	return func(e *Excel) {
+		e.headerRange = ref
+	}
+}
+
+/**
+DataStartRow set the row where the data begins, it begins with 1,
+the row after the header is used by default
+*/
+func DataStartRow(row int) Option {
+	return func(e *Excel) {
+		e.dataStartRow = row
+	}
+}
+
+/**
+DetectHeader find the header by the tag paths of templates, the row which all the leaf titles
+end at is the last header row, a leaf title can be merged downward to it.
+Note: templates must be struct pointer types
+*/
+func DetectHeader(templates ...interface{}) Option {
+	return func(e *Excel) {
+		e.headerTemplates = append(e.headerTemplates, templates...)
+	}
+}
+
+/**
+NoHeader treat the sheets as having no header, the data begins at the first row, and the fields are bound by
+the position tags, ex: `excel:"col=C"` or `excel:"#3"`. When exporting, the header is not written.
+*/
+func NoHeader() Option {
+	return func(e *Excel) {
+		e.noHeader = true
+	}
+}
+
+/**
+StopAt stop reading the data rows at the first row matching the filter, the row itself is excluded
+*/
+func StopAt(filter RowFilter) Option {
+	return func(e *Excel) {
+		e.stopFilters = append(e.stopFilters, filter)
+	}
+}
+
+/**
+StopAtBlankRow stop reading the data rows at the first completely blank row
+*/
+func StopAtBlankRow() Option {
+	return StopAt(IsBlankRow)
+}
+
+/**
+StopAtMarker stop reading the data rows at the first row which has a cell equal to any of markers, ex: "合计"
+*/
+func StopAtMarker(markers ...string) Option {
+	return StopAt(HasCell(markers...))
+}
+
+/**
+SkipRow skip the data rows matching the filter
+*/
+func SkipRow(filter RowFilter) Option {
+	return func(e *Excel) {
+		e.skipFilters = append(e.skipFilters, filter)
+	}
+}
+
+/**
+SkipBlankRows skip the data rows which are completely blank
+*/
+func SkipBlankRows() Option {
+	return SkipRow(IsBlankRow)
+}
+
+/**
+IsBlankRow report whether all the cells of row are blank
+*/
+func IsBlankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+/**
+HasCell return a filter which matches the rows having a cell equal to any of values, the spaces around the cell are ignored
+*/
+func HasCell(values ...string) RowFilter {
+	return func(row []string) bool {
+		for _, cell := range row {
+			cell = strings.TrimSpace(cell)
+			for _, value := range values {
+				if cell == value {
+					return true
+				}
+			}
+		}
+		return false
+	}
+}
+
+/**
+CellHasPrefix return a filter which matches the rows whose first non-blank cell begins with any of prefixes, ex: "说明"
+*/
+func CellHasPrefix(prefixes ...string) RowFilter {
+	return func(row []string) bool {
+		for _, cell := range row {
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue
+			}
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(cell, prefix) {
+					return true
+				}
+			}
+			return false
+		}
+		return false
+	}
+}
+
+/**
+FillMergedCells copy the value of the merged cells in the data rows to every cell they cover
+*/
+func FillMergedCells() Option {
+	return func(e *Excel) {
+		e.fillMergedCells = true
+	}
+}
+
+/**
+MergeSameValues merge the consecutive cells which have the same value in the columns of paths when exporting,
+ex: MergeSameValues("订单|客户")
+*/
+func MergeSameValues(paths ...string) Option {
+	return func(e *Excel) {
+		e.mergePaths = append(e.mergePaths, paths...)
+	}
+}
+
+/**
+EvalFormulas calculate the formulas of the data cells instead of using the cached values
+*/
+func EvalFormulas() Option {
+	return func(e *Excel) {
+		e.evalFormulas = true
+	}
+}
+
+/**
+Footer append a footer row to every sheet when exporting, the title is written to the first column
+if it's not aggregated, ex: Footer("合计", Sum("订单|金额"), Count("订单|编号"))
+*/
+func Footer(title string, aggregations ...Aggregation) Option {
+	return func(e *Excel) {
+		e.footer = &footer{title: title, aggregations: aggregations}
+	}
+}
+
+/**
+FooterValues write the precomputed values to the footer and subtotal rows instead of excel formulas
+*/
+func FooterValues() Option {
+	return func(e *Excel) {
+		e.footerFormula = false
+	}
+}
+
+/**
+GroupBy group the consecutive rows which have the same values of keys when exporting, the rows are
+grouped by keys[0] first, and then by keys[1] in every group, and so on. A subtotal row is written
+below every group, and the rows are outlined so that the groups can be collapsed in excel.
+ex: GroupBy([]string{"订单|地区", "订单|月份"}, Sum("订单|金额"))
+*/
+func GroupBy(keys []string, aggregations ...Aggregation) Option {
+	return func(e *Excel) {
+		if e.groupBy == nil {
+			e.groupBy = new(groupBy)
+		}
+		e.groupBy.keys = keys
+		e.groupBy.aggregations = aggregations
+	}
+}
+
+/**
+MergeGroupKeys merge the cells of the group keys in every group, it works with GroupBy
+*/
+func MergeGroupKeys() Option {
+	return func(e *Excel) {
+		if e.groupBy == nil {
+			e.groupBy = new(groupBy)
+		}
+		e.groupBy.mergeKeys = true
+	}
+}
+
+/**
+MaxRowsPerSheet split the rows to the sheets which have at most n data rows when exporting,
+the sheets are named by the sheet prefix and a number. n is limited by the max rows of an excel sheet.
+*/
+func MaxRowsPerSheet(n int) Option {
+	return func(e *Excel) {
+		e.partition = maxRowsPartition(n)
+	}
+}
+
+/**
+SplitByField put the rows to the sheets named by the value of the field of path when exporting,
+ex: SplitByField("订单|地区")
+*/
+func SplitByField(path string) Option {
+	return func(e *Excel) {
+		e.partition = fieldPartition(path)
+	}
+}
+
+/**
+SplitBy put the rows to the sheets named by sheetName when exporting, the invalid characters of the names are
+replaced by "_", and the different names which are the same after replaced are suffixed, ex: "a/b" and "a:b" are
+"a_b" and "a_b_2"
+*/
+func SplitBy(sheetName func(row interface{}) string) Option {
+	return func(e *Excel) {
+		e.partition = keyPartition(func(row interface{}) (string, error) {
+			return sheetName(row), nil
+		})
+	}
+}
+
+/**
+HeaderStyle set the style of the header cells when exporting
+*/
+func HeaderStyle(style *excelize.Style) Option {
+	return func(e *Excel) {
+		e.headerStyle = style
+	}
+}
+
+/**
+CSVDelimiter set the field delimiter of CSV, ',' by default, use '\t' for TSV
+*/
+func CSVDelimiter(delimiter rune) Option {
+	return func(e *Excel) {
+		e.csv.delimiter = delimiter
+	}
+}
+
+/**
+CSVEncoding set the encoding of CSV, the byte order mark is skipped when reading a UTF-8 CSV
+*/
+func CSVEncoding(encoding Encoding) Option {
+	return func(e *Excel) {
+		e.csv.encoding = encoding
+	}
+}
+
+/**
+CSVLazyQuotes allow a quote to appear in an unquoted field and a non-doubled quote to appear in a quoted field
+when reading CSV
+*/
+func CSVLazyQuotes() Option {
+	return func(e *Excel) {
+		e.csv.lazyQuotes = true
+	}
+}
+
+/**
+CSVQuoteAll quote all the fields when writing CSV
+*/
+func CSVQuoteAll() Option {
+	return func(e *Excel) {
+		e.csv.quoteAll = true
+	}
+}
+
+/**
+OnProgress report the progress of opening, building the headers, scanning, writing and saving to fn,
+the reports of the rows are at most once per interval, 200ms by default. Every phase ends with a report whose
+Done is true, and the concurrent scans are reported separately, so fn may be called concurrently.
+*/
+func OnProgress(fn func(Progress), interval ...time.Duration) Option {
+	return onProgress(func(p Progress, _ bool) {
+		fn(p)
+	}, interval...)
+}
+
+/**
+ProgressChan report the progress to ch the same as OnProgress, the reports of the rows between the beginning and
+the end of a phase are dropped if ch is full, the others are always sent, so ch must be received until the work is done
+*/
+func ProgressChan(ch chan<- Progress, interval ...time.Duration) Option {
+	return onProgress(func(p Progress, interim bool) {
+		if !interim {
+			ch <- p
+			return
+		}
+		select {
+		case ch <- p:
+		default:
+		}
+	}, interval...)
+}
+
+func onProgress(fn func(p Progress, interim bool), interval ...time.Duration) Option {
+	return func(e *Excel) {
+		e.progress = &progressReporter{fn: fn, interval: _progressInterval}
+		if len(interval) > 0 {
+			e.progress.interval = interval[0]
+		}
+	}
+}
+
+/**
+Use add the middlewares which are called in order for every row scanned by ScanRow, AsyncScanRows, ScanSheetRows
+and ScanSheets, and every row exported by NewExcelFromData
+*/
+func Use(middlewares ...Middleware) Option {
+	return func(e *Excel) {
+		e.middlewares = append(e.middlewares, middlewares...)
+	}
+}
@@ -0,0 +1,253 @@
+package excel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+/**
+sheetPart is the rows written to a sheet
+*/
+type sheetPart struct {
+	name string
+	rows []interface{}
+}
+
+/**
+partition split the rows to sheets in order
+*/
+type partition func(e *Excel, rows []interface{}) ([]*sheetPart, error)
+
+/**
+partitionRows split the rows to sheets, the rows are divided evenly by the sheet count by default
+*/
+func (e *Excel) partitionRows(rows []interface{}) (parts []*sheetPart, err error) {
+	p := e.partition
+	if p == nil {
+		p = evenPartition
+	}
+	if parts, err = p(e, rows); err != nil {
+		return
+	}
+
+	if len(parts) == 0 {
+		parts = append(parts, &sheetPart{name: fmt.Sprintf("%s%d", e.sheetPrefix, 1)})
+	}
+	return
+}
+
+func evenPartition(e *Excel, rows []interface{}) (parts []*sheetPart, err error) {
+	l := len(rows)
+	if l != 0 && l < e.sheetCount {
+		err = errors.New("data rows is smaller than sheet count")
+		return
+	}
+
+	sheetRowSize := l / e.sheetCount
+	for idx := 0; idx < e.sheetCount; idx++ {
+		part := &sheetPart{name: fmt.Sprintf("%s%d", e.sheetPrefix, idx+1)}
+		if idx == e.sheetCount-1 {
+			part.rows = rows[idx*sheetRowSize:]
+		} else {
+			part.rows = rows[idx*sheetRowSize : (idx+1)*sheetRowSize]
+		}
+		parts = append(parts, part)
+	}
+
+	return
+}
+
+/**
+maxRowsPartition split the rows to sheets which have at most n data rows, n is limited by the max rows
+of an excel sheet, the header, the footer and the subtotal rows of the groups are excluded
+*/
+func maxRowsPartition(n int) partition {
+	return func(e *Excel, rows []interface{}) (parts []*sheetPart, err error) {
+		limit, err := e.sheetRowsLimit(rows)
+		if err != nil {
+			return
+		}
+		return e.splitRows(rows, n, limit)
+	}
+}
+
+/**
+sheetRowsLimit return the max rows of a sheet except the header and the footer
+*/
+func (e *Excel) sheetRowsLimit(rows []interface{}) (limit int, err error) {
+	limit = _maxSheetRows
+	if len(rows) != 0 && !e.noHeader {
+		var h *header
+		if h, err = parseHeader(rows[0]); err != nil {
+			err = errors.Wrap(err, "parseHeader")
+			return
+		}
+		// the dummy root is not written
+		limit -= h.getHeight() - 1
+	}
+	if e.footer != nil {
+		limit--
+	}
+	return
+}
+
+/**
+splitRows split the rows to sheets which have at most n data rows, and at most limit rows including the subtotal rows
+*/
+func (e *Excel) splitRows(rows []interface{}, n, limit int) (parts []*sheetPart, err error) {
+	if n <= 0 || n > limit {
+		n = limit
+	}
+	newGroups, err := e.newGroupsOf(rows)
+	if err != nil {
+		return
+	}
+
+	var (
+		part *sheetPart
+		// the rows of part including the subtotal rows
+		used int
+	)
+	for i, row := range rows {
+		need := 1
+		if part != nil {
+			need += newGroups(rows[i-1], row)
+		}
+		if part == nil || len(part.rows) == n || used+need > limit {
+			part = &sheetPart{name: fmt.Sprintf("%s%d", e.sheetPrefix, len(parts)+1)}
+			parts = append(parts, part)
+			used, need = 0, 1+newGroups(nil, row)
+		}
+		part.rows = append(part.rows, row)
+		used += need
+	}
+	return
+}
+
+/**
+newGroupsOf return the func which count the groups begin at row after prev, a subtotal row is written for every
+group of every level, all the groups begin at the first row of a sheet whose prev is nil
+*/
+func (e *Excel) newGroupsOf(rows []interface{}) (newGroups func(prev, row interface{}) int, err error) {
+	if !e.isGrouped() || len(rows) == 0 {
+		return func(prev, row interface{}) int { return 0 }, nil
+	}
+
+	t := reflect.Indirect(reflect.ValueOf(rows[0])).Type()
+	keyIndices := make([]int, len(e.groupBy.keys))
+	for i, key := range e.groupBy.keys {
+		if keyIndices[i] = fieldIndexByPath(t, key); keyIndices[i] < 0 {
+			err = errors.Errorf("path %s is not found in %s", key, t.Name())
+			return
+		}
+	}
+
+	newGroups = func(prev, row interface{}) int {
+		if prev == nil {
+			return len(keyIndices)
+		}
+		prevValue, value := reflect.Indirect(reflect.ValueOf(prev)), reflect.Indirect(reflect.ValueOf(row))
+		for level, keyIndex := range keyIndices {
+			// the groups of the level and the deeper levels begin if the key changes
+			if !reflect.DeepEqual(exportValue(prevValue.Field(keyIndex)), exportValue(value.Field(keyIndex))) {
+				return len(keyIndices) - level
+			}
+		}
+		return 0
+	}
+	return
+}
+
+/**
+keyPartition put the rows to the sheets named by sheetName, the sheets are in order of first appearance,
+the different names which are the same after sanitized are suffixed by the order, ex: "a/b" and "a:b" are "a_b" and "a_b_2"
+*/
+func keyPartition(sheetName func(row interface{}) (string, error)) partition {
+	return func(e *Excel, rows []interface{}) (parts []*sheetPart, err error) {
+		var (
+			// the parts by the names returned by sheetName
+			partMap = make(map[string]*sheetPart)
+			// the sheet names are case insensitive
+			used = make(map[string]bool)
+		)
+		for _, row := range rows {
+			var key string
+			if key, err = sheetName(row); err != nil {
+				return
+			}
+
+			part, ok := partMap[key]
+			if !ok {
+				name := sanitizeSheetName(key)
+				if name == "" {
+					name = e.sheetPrefix
+				}
+				part = &sheetPart{name: UniqueSheetName(name, used)}
+				partMap[key] = part
+				parts = append(parts, part)
+			}
+			part.rows = append(part.rows, row)
+		}
+		return
+	}
+}
+
+/**
+UniqueSheetName suffix name by the order if it's used, ex: 订单_2, and mark it used. The names are compared
+case-insensitively as excel does, so used is keyed by the lower case names, and the suffixed names are
+truncated to the max length of the sheet name.
+*/
+func UniqueSheetName(name string, used map[string]bool) string {
+	unique := name
+	for i := 2; used[strings.ToLower(unique)]; i++ {
+		suffix := fmt.Sprintf("_%d", i)
+		unique = truncateSheetName(name, _maxSheetNameLength-len(suffix)) + suffix
+	}
+	used[strings.ToLower(unique)] = true
+	return unique
+}
+
+/**
+fieldPartition put the rows to the sheets named by the value of the field of path
+*/
+func fieldPartition(path string) partition {
+	return keyPartition(func(row interface{}) (name string, err error) {
+		v := reflect.Indirect(reflect.ValueOf(row))
+		fieldIndex := fieldIndexByPath(v.Type(), path)
+		if fieldIndex < 0 {
+			err = errors.Errorf("path %s is not found in %s", path, v.Type().Name())
+			return
+		}
+
+		name = fmt.Sprint(exportValue(v.Field(fieldIndex)))
+		return
+	})
+}
+
+/**
+sanitizeSheetName replace the characters which are invalid in a sheet name, and limit the length
+*/
+func sanitizeSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	return truncateSheetName(name, _maxSheetNameLength)
+}
+
+/**
+truncateSheetName limit the name to n characters
+*/
+func truncateSheetName(name string, n int) string {
+	for utf8.RuneCountInString(name) > n {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
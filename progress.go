@@ -0,0 +1,192 @@
+package excel
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type ProgressPhase int
+
+const (
+	// PhaseOpen is opening the file
+	PhaseOpen ProgressPhase = iota + 1
+	// PhaseHeader is building the header of a sheet
+	PhaseHeader
+	// PhaseScan is reading and scanning the data rows
+	PhaseScan
+	// PhaseWrite is writing the rows to a sheet
+	PhaseWrite
+	// PhaseSave is saving the file
+	PhaseSave
+)
+
+func (p ProgressPhase) String() string {
+	switch p {
+	case PhaseOpen:
+		return "open"
+	case PhaseHeader:
+		return "header"
+	case PhaseScan:
+		return "scan"
+	case PhaseWrite:
+		return "write"
+	case PhaseSave:
+		return "save"
+	default:
+		return "unknown"
+	}
+}
+
+/**
+Progress is reported when a phase begins, every interval while the rows are processed, and when the phase ends
+*/
+type Progress struct {
+	Phase ProgressPhase
+	// Sheet is empty for opening and saving
+	Sheet string
+	// Rows is the count of the processed rows
+	Rows int
+	// Total is the count of all the rows, it's 0 if unknown
+	Total int
+	// Done is true for the end of the phase
+	Done bool
+}
+
+/**
+progressReporter report the progress of the operations to fn, every operation reports by its own phaseProgress,
+all the methods do nothing on a nil reporter
+*/
+type progressReporter struct {
+	// interim is true for the reports of the rows between the beginning and the end of a phase
+	fn       func(p Progress, interim bool)
+	interval time.Duration
+}
+
+/**
+start begin a phase of an operation and report it
+*/
+func (r *progressReporter) start(phase ProgressPhase, sheet string, total int) *phaseProgress {
+	if r == nil {
+		return nil
+	}
+
+	p := &phaseProgress{reporter: r, total: int64(total), current: Progress{Phase: phase, Sheet: sheet, Total: total}}
+	p.mu.Lock()
+	report := p.snapshot(true)
+	p.mu.Unlock()
+	r.fn(report, false)
+	return p
+}
+
+/**
+phaseProgress report a phase of an operation at most once per interval except the beginning and the end,
+all the methods do nothing on a nil phaseProgress
+*/
+type phaseProgress struct {
+	reporter *progressReporter
+	total    int64
+
+	// rows is counted atomically, so add doesn't lock until due is set
+	rows int64
+	// due is set by timer when the interval passed
+	due int32
+
+	mu      sync.Mutex
+	current Progress
+	timer   *time.Timer
+}
+
+/**
+add count n processed rows, it's reported if the interval passed, and the phase ends if all the rows are done
+*/
+func (p *phaseProgress) add(n int) {
+	if p == nil {
+		return
+	}
+
+	done := atomic.AddInt64(&p.rows, int64(n)) == p.total
+	if !done && atomic.LoadInt32(&p.due) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	if p.current.Done || !done && !atomic.CompareAndSwapInt32(&p.due, 1, 0) {
+		// the phase ended, or the other rows are reported since due is set
+		p.mu.Unlock()
+		return
+	}
+	p.current.Done = done
+	report := p.snapshot(!done)
+	p.mu.Unlock()
+	p.reporter.fn(report, !done)
+}
+
+/**
+finish end the phase and report it if it's not ended by add
+*/
+func (p *phaseProgress) finish() {
+	if p == nil {
+		return
+	}
+
+	p.mu.Lock()
+	if p.current.Done {
+		p.mu.Unlock()
+		return
+	}
+	p.current.Done = true
+	report := p.snapshot(false)
+	p.mu.Unlock()
+	p.reporter.fn(report, false)
+}
+
+/**
+snapshot return the current progress to report and reset the timer, the timer is stopped if not next,
+it's called with p.mu locked
+*/
+func (p *phaseProgress) snapshot(next bool) Progress {
+	atomic.StoreInt32(&p.due, 0)
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	if next {
+		p.timer = time.AfterFunc(p.reporter.interval, func() {
+			atomic.StoreInt32(&p.due, 1)
+		})
+	}
+
+	report := p.current
+	report.Rows = int(atomic.LoadInt64(&p.rows))
+	return report
+}
+
+/**
+SaveAs save the file to name, the save phase is reported
+*/
+func (e *Excel) SaveAs(name string) (err error) {
+	p := e.progress.start(PhaseSave, "", 0)
+	if err = e.ex.SaveAs(name); err != nil {
+		err = errors.Wrap(err, "e.ex.SaveAs")
+		return
+	}
+	p.finish()
+	return
+}
+
+/**
+WriteTo write the file to w, the save phase is reported
+*/
+func (e *Excel) WriteTo(w io.Writer) (n int64, err error) {
+	p := e.progress.start(PhaseSave, "", 0)
+	if n, err = e.ex.WriteTo(w); err != nil {
+		err = errors.Wrap(err, "e.ex.WriteTo")
+		return
+	}
+	p.finish()
+	return
+}
@@ -0,0 +1,264 @@
+package excel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+)
+
+type HeaderIssueKind int
+
+const (
+	// HeaderMissing the struct expects a column which the sheet doesn't have
+	HeaderMissing HeaderIssueKind = iota + 1
+	// HeaderUnexpected the sheet has a column which the struct doesn't expect
+	HeaderUnexpected
+	// HeaderMisordered the column exists but is not at the expected position
+	HeaderMisordered
+	// HeaderDuplicated the column appears more than once in the sheet
+	HeaderDuplicated
+)
+
+func (k HeaderIssueKind) String() string {
+	switch k {
+	case HeaderMissing:
+		return "缺少列"
+	case HeaderUnexpected:
+		return "多余列"
+	case HeaderMisordered:
+		return "列顺序错误"
+	case HeaderDuplicated:
+		return "重复列"
+	default:
+		return "未知问题"
+	}
+}
+
+type HeaderIssue struct {
+	Kind HeaderIssueKind
+	// Path is the header path of the column, ex: ["字段", "字段1"]
+	Path []string
+	// Axis is the cell of the column header in the sheet, for missing column
+	// it's the cell where the column is expected to be.
+	Axis string
+	// ExpectedAxis is the cell where the column should be, only set for misordered column
+	ExpectedAxis string
+}
+
+func (i *HeaderIssue) String() string {
+	path := strings.Join(i.Path, _tagPathSplitter)
+	switch i.Kind {
+	case HeaderMissing:
+		return fmt.Sprintf("%s：%s，应位于 %s", i.Kind, path, i.Axis)
+	case HeaderMisordered:
+		return fmt.Sprintf("%s：%s，当前位于 %s，应位于 %s", i.Kind, path, i.Axis, i.ExpectedAxis)
+	default:
+		return fmt.Sprintf("%s：%s，位于 %s", i.Kind, path, i.Axis)
+	}
+}
+
+type SheetHeaderReport struct {
+	Sheet  string
+	Issues []*HeaderIssue
+}
+
+/**
+IsConsistent return whether the sheet header matches the responses exactly
+*/
+func (r *SheetHeaderReport) IsConsistent() bool {
+	return len(r.Issues) == 0
+}
+
+func (r *SheetHeaderReport) String() string {
+	if r.IsConsistent() {
+		return fmt.Sprintf("工作表 %s：表头正确", r.Sheet)
+	}
+
+	lines := make([]string, 0, len(r.Issues)+1)
+	lines = append(lines, fmt.Sprintf("工作表 %s：表头有 %d 处错误", r.Sheet, len(r.Issues)))
+	for _, issue := range r.Issues {
+		lines = append(lines, "  "+issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+type HeaderReport struct {
+	Sheets []*SheetHeaderReport
+}
+
+/**
+IsConsistent return whether all the sheet headers match the responses exactly
+*/
+func (r *HeaderReport) IsConsistent() bool {
+	for _, sheet := range r.Sheets {
+		if !sheet.IsConsistent() {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *HeaderReport) String() string {
+	lines := make([]string, 0, len(r.Sheets))
+	for _, sheet := range r.Sheets {
+		lines = append(lines, sheet.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+/**
+HeaderReport compare the header leaves of the sheet with the tag paths of responses, and list
+the missing, unexpected, misordered and duplicated columns.
+Note: responses must be struct pointer types
+*/
+func (root *Importer) HeaderReport(responses ...interface{}) (report *SheetHeaderReport, err error) {
+	var expected [][]string
+	for _, resp := range responses {
+		t := reflect.TypeOf(resp)
+		if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+			err = errors.New("response is not struct ptr type")
+			return
+		}
+
+		t = t.Elem()
+		for i := 0; i < t.NumField(); i++ {
+			tag := t.Field(i).Tag.Get(_tagFlag)
+			if tag == "" || isColTag(tag) {
+				continue
+			}
+			expected = append(expected, strings.Split(tag, _tagPathSplitter))
+		}
+	}
+
+	// a root without children means the sheet has no header at all
+	var leafNodes []*Importer
+	if len(root.childImporters) != 0 {
+		leafNodes = root.getLeafNodes()
+	}
+
+	report = &SheetHeaderReport{Sheet: root.value}
+
+	// match every expected path to the first unused leaf with the same path
+	leafQueues := make(map[string][]int)
+	for j, leafNode := range leafNodes {
+		key := strings.Join(leafNode.path, _tagPathSplitter)
+		leafQueues[key] = append(leafQueues[key], j)
+	}
+	expectedKeys := make(map[string]bool)
+	matched := make([]int, len(expected))
+	leafMatched := make([]bool, len(leafNodes))
+	for i, path := range expected {
+		key := strings.Join(path, _tagPathSplitter)
+		expectedKeys[key] = true
+		queue := leafQueues[key]
+		if len(queue) == 0 {
+			matched[i] = -1
+			continue
+		}
+		matched[i] = queue[0]
+		leafMatched[queue[0]] = true
+		leafQueues[key] = queue[1:]
+	}
+
+	for i, path := range expected {
+		if matched[i] >= 0 {
+			continue
+		}
+		var axis string
+		if axis, err = root.expectedAxis(leafNodes, i); err != nil {
+			return
+		}
+		report.Issues = append(report.Issues, &HeaderIssue{Kind: HeaderMissing, Path: path, Axis: axis})
+	}
+
+	for j, leafNode := range leafNodes {
+		if leafMatched[j] {
+			continue
+		}
+		kind := HeaderUnexpected
+		if expectedKeys[strings.Join(leafNode.path, _tagPathSplitter)] {
+			kind = HeaderDuplicated
+		}
+		var axis string
+		if axis, err = excelize.CoordinatesToCellName(leafNode.colIndexStart, leafNode.rowIndexStart); err != nil {
+			err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+			return
+		}
+		report.Issues = append(report.Issues, &HeaderIssue{Kind: kind, Path: leafNode.path, Axis: axis})
+	}
+
+	// the matched columns which are not in the longest ordered sequence are misordered
+	var pairs []int
+	for i := range expected {
+		if matched[i] >= 0 {
+			pairs = append(pairs, i)
+		}
+	}
+	inOrder := longestIncreasing(pairs, func(i int) int { return matched[i] })
+	for _, i := range pairs {
+		if inOrder[i] {
+			continue
+		}
+		leafNode := leafNodes[matched[i]]
+		issue := &HeaderIssue{Kind: HeaderMisordered, Path: expected[i]}
+		if issue.Axis, err = excelize.CoordinatesToCellName(leafNode.colIndexStart, leafNode.rowIndexStart); err != nil {
+			err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+			return
+		}
+		if issue.ExpectedAxis, err = root.expectedAxis(leafNodes, i); err != nil {
+			return
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	return
+}
+
+/**
+expectedAxis return the cell where the i-th expected column should be
+*/
+func (root *Importer) expectedAxis(leafNodes []*Importer, i int) (axis string, err error) {
+	col, row := root.colIndexStart+i, root.getRowsBeginIndex()
+	if i < len(leafNodes) {
+		col = leafNodes[i].colIndexStart
+	} else if l := len(leafNodes); l > 0 {
+		col = leafNodes[l-1].colIndexEnd + i - l + 1
+	}
+	if row == 0 {
+		row = 1
+	}
+
+	if axis, err = excelize.CoordinatesToCellName(col, row); err != nil {
+		err = errors.Wrap(err, "excelize.CoordinatesToCellName")
+	}
+	return
+}
+
+/**
+longestIncreasing return the items which make up the longest sequence whose values are increasing
+*/
+func longestIncreasing(items []int, value func(int) int) map[int]bool {
+	n := len(items)
+	lengths, prev := make([]int, n), make([]int, n)
+	best := -1
+	for i := 0; i < n; i++ {
+		lengths[i], prev[i] = 1, -1
+		for j := 0; j < i; j++ {
+			if value(items[j]) < value(items[i]) && lengths[j]+1 > lengths[i] {
+				lengths[i], prev[i] = lengths[j]+1, j
+			}
+		}
+		if best < 0 || lengths[i] > lengths[best] {
+			best = i
+		}
+	}
+
+	res := make(map[int]bool, n)
+	for i := best; i >= 0; i = prev[i] {
+		res[items[i]] = true
+	}
+	return res
+}
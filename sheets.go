@@ -0,0 +1,218 @@
+package excel
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+/**
+SheetBinding bind a sheet to a struct type, Dest is a pointer to a slice of struct pointers, ex: &[]*order{}
+*/
+type SheetBinding struct {
+	Sheet string
+	Dest  interface{}
+}
+
+/**
+SheetsResult is the result of ScanSheets
+*/
+type SheetsResult struct {
+	// Errors are the errors of each sheet, the row errors are *CellError with the row index
+	Errors map[string][]error
+
+	// the scanned slice and the row indices of its records by sheet
+	dests      map[string]reflect.Value
+	rowIndices map[string][]int
+	// linked are the relations linked, a relation is linked once so the children are not attached twice
+	linked map[Relation]bool
+}
+
+/**
+HasErrors return whether any sheet has error
+*/
+func (r *SheetsResult) HasErrors() bool {
+	for _, errs := range r.Errors {
+		if len(errs) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ScanSheets scan every sheet to the struct type it's bound to in one call, the rows which fail to scan are
+not appended to Dest, and their errors are collected in the result by sheet.
+*/
+func (e *Excel) ScanSheets(bindings ...SheetBinding) (result *SheetsResult, err error) {
+	dests := make([]reflect.Value, 0, len(bindings))
+	for _, binding := range bindings {
+		var dest reflect.Value
+		if dest, err = bindingDest(binding); err != nil {
+			return
+		}
+		dests = append(dests, dest)
+	}
+
+	result = &SheetsResult{
+		Errors:     make(map[string][]error, len(bindings)),
+		dests:      make(map[string]reflect.Value, len(bindings)),
+		rowIndices: make(map[string][]int, len(bindings)),
+		linked:     make(map[Relation]bool),
+	}
+	for i, binding := range bindings {
+		dest, sheet := dests[i], binding.Sheet
+		result.dests[sheet] = dest
+		importer, err := e.importerOf(sheet)
+		if err != nil {
+			result.Errors[sheet] = append(result.Errors[sheet], err)
+			continue
+		}
+
+		walkErr := e.WalkSheetRows(sheet, func(rowIndex int, row []string) error {
+			resp := reflect.New(dest.Type().Elem().Elem())
+			if err := e.scanRow(importer, rowIndex, row, []interface{}{resp.Interface()}); err != nil {
+				result.Errors[sheet] = append(result.Errors[sheet], withRowIndex(err, rowIndex))
+				return nil
+			}
+
+			dest.Set(reflect.Append(dest, resp))
+			result.rowIndices[sheet] = append(result.rowIndices[sheet], rowIndex)
+			return nil
+		})
+		if walkErr != nil {
+			result.Errors[sheet] = append(result.Errors[sheet], errors.Wrap(walkErr, "e.WalkSheetRows"))
+		}
+	}
+
+	return
+}
+
+/**
+bindingDest return the slice which Dest of the binding points to
+*/
+func bindingDest(binding SheetBinding) (dest reflect.Value, err error) {
+	t := reflect.TypeOf(binding.Dest)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Slice ||
+		t.Elem().Elem().Kind() != reflect.Ptr || t.Elem().Elem().Elem().Kind() != reflect.Struct {
+		err = errors.Errorf("dest of sheet %s is not a pointer to a slice of struct pointers", binding.Sheet)
+		return
+	}
+
+	dest = reflect.ValueOf(binding.Dest).Elem()
+	return
+}
+
+/**
+Relation declare a foreign key from the Child sheet to the Parent sheet, the keys are the tag paths
+of the fields, ex: Relation{Parent: "订单", ParentKey: "订单号", Child: "明细", ChildKey: "明细|订单号", Attach: "Items"}
+*/
+type Relation struct {
+	Parent    string
+	ParentKey string
+	Child     string
+	ChildKey  string
+	// Attach is the name of the parent field which the children are appended to, the field must be a slice of
+	// the child struct pointers, ex: Items []*orderItem. It's optional.
+	Attach string
+}
+
+/**
+Link check that every child key of relations refers to an existing parent key, the blank child keys are ignored.
+The dangling references are returned as *CellError and also added to the errors of the child sheet.
+The children are attached to their parents if Attach is set. A relation can be linked only once.
+*/
+func (r *SheetsResult) Link(relations ...Relation) (errs []error, err error) {
+	for _, relation := range relations {
+		if r.linked[relation] {
+			err = errors.Errorf("relation from sheet %s to sheet %s is linked", relation.Child, relation.Parent)
+			return
+		}
+
+		var relationErrs []error
+		if relationErrs, err = r.link(relation); err != nil {
+			return
+		}
+		r.linked[relation] = true
+		r.Errors[relation.Child] = append(r.Errors[relation.Child], relationErrs...)
+		errs = append(errs, relationErrs...)
+	}
+
+	return
+}
+
+func (r *SheetsResult) link(relation Relation) (errs []error, err error) {
+	parents, ok := r.dests[relation.Parent]
+	if !ok {
+		err = errors.Errorf("sheet %s is not scanned", relation.Parent)
+		return
+	}
+	children, ok := r.dests[relation.Child]
+	if !ok {
+		err = errors.Errorf("sheet %s is not scanned", relation.Child)
+		return
+	}
+
+	parentType, childType := parents.Type().Elem().Elem(), children.Type().Elem().Elem()
+	parentKeyIndex := fieldIndexByPath(parentType, relation.ParentKey)
+	if parentKeyIndex < 0 {
+		err = errors.Errorf("path %s is not found in %s", relation.ParentKey, parentType.Name())
+		return
+	}
+	childKeyIndex := fieldIndexByPath(childType, relation.ChildKey)
+	if childKeyIndex < 0 {
+		err = errors.Errorf("path %s is not found in %s", relation.ChildKey, childType.Name())
+		return
+	}
+	attachIndex := -1
+	if relation.Attach != "" {
+		field, ok := parentType.FieldByName(relation.Attach)
+		if !ok || len(field.Index) != 1 || field.Type != reflect.SliceOf(children.Type().Elem()) {
+			err = errors.Errorf("field %s of %s is not a slice of %s", relation.Attach, parentType.Name(), children.Type().Elem())
+			return
+		}
+		attachIndex = field.Index[0]
+	}
+
+	parentMap := make(map[string]reflect.Value, parents.Len())
+	for i := 0; i < parents.Len(); i++ {
+		parent := parents.Index(i)
+		key := fmt.Sprint(exportValue(parent.Elem().Field(parentKeyIndex)))
+		if _, ok := parentMap[key]; !ok {
+			parentMap[key] = parent
+		}
+	}
+
+	childPaths := strings.Split(childType.Field(childKeyIndex).Tag.Get(_tagFlag), _tagPathSplitter)
+	for i := 0; i < children.Len(); i++ {
+		child := children.Index(i)
+		keyField := child.Elem().Field(childKeyIndex)
+		key := fmt.Sprint(exportValue(keyField))
+		if strings.TrimSpace(key) == "" {
+			continue
+		}
+
+		parent, ok := parentMap[key]
+		if !ok {
+			cellErr := &CellError{
+				rowIndex: r.rowIndices[relation.Child][i],
+				paths:    childPaths,
+				err:      errors.Errorf("%s %s is not found in sheet %s", relation.ParentKey, key, relation.Parent),
+			}
+			if field, ok := keyField.Interface().(Field); ok {
+				cellErr.colIndex = field.ColIndex()
+			}
+			errs = append(errs, cellErr)
+			continue
+		}
+
+		if attachIndex >= 0 {
+			attach := parent.Elem().Field(attachIndex)
+			attach.Set(reflect.Append(attach, child))
+		}
+	}
+
+	return
+}
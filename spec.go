@@ -0,0 +1,406 @@
+package excel
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+/**
+Spec declares the columns of a sheet without Go structs, it's loaded from YAML or JSON, ex:
+
+	sheet: 订单
+	headerRow: 2
+	columns:
+	  - path: 订单|编号
+	    name: id
+	    type: int
+	    required: true
+	  - path: 订单|状态
+	    enum: {待支付: unpaid, 已支付: paid}
+*/
+type Spec struct {
+	Sheet string `yaml:"sheet" json:"sheet"`
+	// HeaderRow is the last header row, it's applied by the option SpecHeader when opening, and the scans by the spec
+	// fail if the header doesn't end at it. It's optional.
+	HeaderRow int           `yaml:"headerRow" json:"headerRow"`
+	Columns   []*SpecColumn `yaml:"columns" json:"columns"`
+
+	// respType is the struct whose fields are the columns, it's scanned the same as the tagged structs
+	respType reflect.Type
+}
+
+type SpecColumn struct {
+	// Path is the header path, the same as the excel tag
+	Path string `yaml:"path" json:"path"`
+	// Name is the key of the column in the record, it's Path by default
+	Name string `yaml:"name" json:"name"`
+	// Type is one of string, int, int64, float, bool and time, it's string by default
+	Type string `yaml:"type" json:"type"`
+	// Format is the Go time layout of a time column, it's "2006-01-02" by default
+	Format string `yaml:"format" json:"format"`
+	// Default is the cell value if the cell is blank
+	Default  string `yaml:"default" json:"default"`
+	Required bool   `yaml:"required" json:"required"`
+	// Min and Max limit the number columns
+	Min *float64 `yaml:"min" json:"min"`
+	Max *float64 `yaml:"max" json:"max"`
+	// Pattern is the regexp which the cell value must match
+	Pattern string `yaml:"pattern" json:"pattern"`
+	// Enum maps the cell values to the values of the record, the other values are invalid
+	Enum map[string]string `yaml:"enum" json:"enum"`
+
+	paths   []string
+	pattern *regexp.Regexp
+	// labels are the cell values of the enum by the record values
+	labels map[string]string
+}
+
+/**
+Record is a row scanned by a Spec, the keys are the column names, and the blank cells are nil
+*/
+type Record map[string]interface{}
+
+var _specFieldTypes = map[string]reflect.Type{
+	"string": reflect.TypeOf(StringField{}),
+	"int":    reflect.TypeOf(IntField{}),
+	"int64":  reflect.TypeOf(Int64Field{}),
+	"float":  reflect.TypeOf(FloatField{}),
+	"bool":   reflect.TypeOf(BoolField{}),
+	"time":   reflect.TypeOf(TimeField{}),
+}
+
+/**
+LoadSpec load and compile the spec from YAML or JSON
+*/
+func LoadSpec(reader io.Reader) (s *Spec, err error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		err = errors.Wrap(err, "ioutil.ReadAll")
+		return
+	}
+
+	s = new(Spec)
+	if err = yaml.Unmarshal(data, s); err != nil {
+		err = errors.Wrap(err, "yaml.Unmarshal")
+		return
+	}
+	if err = s.Compile(); err != nil {
+		err = errors.Wrap(err, "s.Compile")
+	}
+	return
+}
+
+func LoadSpecFile(file string) (s *Spec, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		err = errors.Wrap(err, "os.Open")
+		return
+	}
+	defer f.Close()
+
+	return LoadSpec(f)
+}
+
+/**
+Compile check the columns and build the struct type of the spec, it's called by LoadSpec, call it after
+building or changing a spec in code
+*/
+func (s *Spec) Compile() (err error) {
+	if len(s.Columns) == 0 {
+		return errors.New("no column in spec")
+	}
+
+	names := make(map[string]bool, len(s.Columns))
+	fields := make([]reflect.StructField, 0, len(s.Columns))
+	for i, column := range s.Columns {
+		if column.Path == "" {
+			return errors.Errorf("path of column %d is empty", i+1)
+		}
+		column.paths = strings.Split(column.Path, _tagPathSplitter)
+		if column.Name == "" {
+			column.Name = column.Path
+		}
+		if names[column.Name] {
+			return errors.Errorf("column name %s is duplicated", column.Name)
+		}
+		names[column.Name] = true
+
+		if column.Type == "" {
+			column.Type = "string"
+		}
+		t, ok := _specFieldTypes[strings.ToLower(column.Type)]
+		if !ok {
+			return errors.Errorf("type %s of %s is not supported", column.Type, column.Path)
+		}
+		if column.Enum != nil {
+			column.labels = make(map[string]string, len(column.Enum))
+			for label, value := range column.Enum {
+				if other, ok := column.labels[value]; ok {
+					return errors.Errorf("enum value %s of %s is mapped by both %s and %s", value, column.Path, other, label)
+				}
+				column.labels[value] = label
+			}
+		}
+		if column.Pattern != "" {
+			if column.pattern, err = regexp.Compile(column.Pattern); err != nil {
+				return errors.Wrapf(err, "pattern of %s", column.Path)
+			}
+		}
+
+		fields = append(fields, reflect.StructField{
+			Name: fmt.Sprintf("Col%d", i+1),
+			Type: t,
+			Tag:  reflect.StructTag(fmt.Sprintf("%s:%q", _tagFlag, column.Path)),
+		})
+	}
+
+	s.respType = reflect.StructOf(fields)
+	return
+}
+
+/**
+Response return a new struct pointer whose fields are the columns in order, it can be used as the
+responses of ScanRow and HeaderReport
+*/
+func (s *Spec) Response() interface{} {
+	return reflect.New(s.respType).Interface()
+}
+
+/**
+ScanRecord scan the row of the first active sheet to a record by the spec
+*/
+func (e *Excel) ScanRecord(row []string, spec *Spec) (record Record, err error) {
+	importer, err := e.importerAt(_defaultSheetIndex)
+	if err != nil {
+		return
+	}
+	if err = spec.checkHeaderRow(importer); err != nil {
+		return
+	}
+	return spec.scanRecord(importer, row)
+}
+
+/**
+ScanSheetRecords scan the data rows of sheet to records one by one, the sheet of spec is used if sheet
+is empty. fn is called with the record and the first CellError of each row, return an error in fn to stop scanning.
+*/
+func (e *Excel) ScanSheetRecords(sheet string, spec *Spec, fn func(rowIndex int, record Record, err error) error) error {
+	if sheet == "" {
+		sheet = spec.Sheet
+	}
+	importer, err := e.importerOf(sheet)
+	if err != nil {
+		return err
+	}
+	if err = spec.checkHeaderRow(importer); err != nil {
+		return err
+	}
+
+	return e.WalkSheetRows(sheet, func(rowIndex int, row []string) error {
+		record, err := spec.scanRecord(importer, row)
+		return fn(rowIndex, record, withRowIndex(err, rowIndex))
+	})
+}
+
+/**
+checkHeaderRow check that the header of importer ends at the header row of the spec if it's set
+*/
+func (s *Spec) checkHeaderRow(importer *Importer) error {
+	if s.HeaderRow == 0 {
+		return nil
+	}
+	if rowEnd := importer.getRowsBeginIndex(); rowEnd != s.HeaderRow {
+		return errors.Errorf("header of sheet %s ends at row %d, not the header row %d of the spec", importer.value, rowEnd, s.HeaderRow)
+	}
+	return nil
+}
+
+/**
+scanRecord apply the defaults, enums and formats to the cells, scan them by the struct of the spec,
+and check the rules of the columns
+*/
+func (s *Spec) scanRecord(root *Importer, row []string) (record Record, err error) {
+	leafNodes := root.getLeafNodes()
+	// the cells are replaced by the checked values in a copy of row
+	row = append([]string(nil), row...)
+	record = make(Record, len(s.Columns))
+	blank := make([]bool, len(s.Columns))
+	// the times parsed by the formats of the columns, they are not scanned by TimeField which knows only _dateLayout
+	times := make(map[int]time.Time)
+	for i, column := range s.Columns {
+		leafNode := column.leafNode(leafNodes)
+		if leafNode == nil {
+			blank[i] = true
+			continue
+		}
+		cellErr := func(err error) error {
+			return &CellError{colIndex: leafNode.colIndexStart, paths: leafNode.path, err: err}
+		}
+		for len(row) < leafNode.colIndexStart {
+			row = append(row, "")
+		}
+		setCell := func(value string) {
+			row[leafNode.colIndexStart-1] = value
+		}
+
+		value := leafNode.cellOf(row)
+		if strings.TrimSpace(value) == "" {
+			value = column.Default
+		}
+		if blank[i] = strings.TrimSpace(value) == ""; blank[i] {
+			if column.Required {
+				err = cellErr(errors.New("不能为空"))
+				return
+			}
+			setCell("")
+			continue
+		}
+
+		if column.pattern != nil && !column.pattern.MatchString(value) {
+			err = cellErr(errors.Errorf("%s 格式不正确", value))
+			return
+		}
+		if column.Enum != nil {
+			mapped, ok := column.Enum[value]
+			if !ok {
+				err = cellErr(errors.Errorf("%s 不是可选的值", value))
+				return
+			}
+			value = mapped
+		}
+		if column.Format != "" && strings.EqualFold(column.Type, "time") {
+			var t time.Time
+			if t, err = time.ParseInLocation(column.Format, value, time.Local); err != nil {
+				err = cellErr(err)
+				return
+			}
+			times[i], value = t, ""
+		}
+		setCell(value)
+	}
+
+	resp := reflect.New(s.respType)
+	if err = root.ScanRow(row, resp.Interface()); err != nil {
+		return
+	}
+
+	for i, column := range s.Columns {
+		if blank[i] {
+			record[column.Name] = nil
+			continue
+		}
+		if t, ok := times[i]; ok {
+			record[column.Name] = t
+			continue
+		}
+
+		value := resp.Elem().Field(i).Interface().(Field).GetValue()
+		if v, ok := toFloat(value); ok && (column.Min != nil && v < *column.Min || column.Max != nil && v > *column.Max) {
+			leafNode := column.leafNode(leafNodes)
+			err = &CellError{colIndex: leafNode.colIndexStart, paths: leafNode.path, err: errors.Errorf("%v 超出范围", value)}
+			return
+		}
+		record[column.Name] = value
+	}
+	return
+}
+
+/**
+leafNode return the leaf node of the column, the path can be the behind part of the leaf path
+*/
+func (c *SpecColumn) leafNode(leafNodes []*Importer) *Importer {
+	for _, leafNode := range leafNodes {
+		if len(leafNode.path) >= len(c.paths) && reflect.DeepEqual(leafNode.path[len(leafNode.path)-len(c.paths):], c.paths) {
+			return leafNode
+		}
+	}
+	return nil
+}
+
+/**
+NewExcelFromRecords write the header of the spec and the records to a sheet, the enums are written as the
+cell values and the times are written by the format. It's a template if there is no record.
+*/
+func NewExcelFromRecords(spec *Spec, records []Record, options ...Option) (e *Excel, err error) {
+	e = newExcel()
+	for _, option := range options {
+		option(e)
+	}
+
+	root := &header{isDummy: true}
+	for _, column := range spec.Columns {
+		root.addPath(strings.Split(column.Path, _tagPathSplitter))
+	}
+	if e.headerRow == 0 && e.headerRange == "" && len(e.headerTemplates) == 0 {
+		e.headerRow = root.getHeight() - 1
+	}
+
+	e.ex = excelize.NewFile()
+	sheet := spec.Sheet
+	if sheet == "" {
+		sheet = fmt.Sprintf("%s%d", e.sheetPrefix, 1)
+	}
+	if sheet != _defaultSheetName {
+		e.ex.SetSheetName(_defaultSheetName, sheet)
+	}
+
+	initData := func([]interface{}) (err error) {
+		if _, err = e.writeHeader([]string{sheet}, root, 1, 0); err != nil {
+			err = errors.Wrap(err, "e.writeHeader")
+			return
+		}
+
+		cols := make(map[string]int)
+		root.walkLeaves(nil, func(path []string) {
+			cols[strings.Join(path, _tagPathSplitter)] = len(cols) + 1
+		})
+		for i, record := range records {
+			for _, column := range spec.Columns {
+				value, ok := column.exportValue(record[column.Name])
+				if !ok {
+					continue
+				}
+				if err = e.setCellValue(sheet, cols[column.Path], root.getHeight()+i, value); err != nil {
+					return
+				}
+			}
+		}
+		return
+	}
+	if err = e.postInitialize(nil, initData); err != nil {
+		err = errors.Wrapf(err, "s.postInitialize")
+		return
+	}
+
+	return
+}
+
+/**
+exportValue return the cell value of the record value, false is returned for nil
+*/
+func (c *SpecColumn) exportValue(value interface{}) (interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	if label, ok := c.labels[fmt.Sprint(value)]; ok {
+		return label, true
+	}
+	if t, ok := value.(time.Time); ok {
+		layout := c.Format
+		if layout == "" {
+			layout = _dateLayout
+		}
+		return t.Format(layout), true
+	}
+	return value, true
+}
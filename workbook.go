@@ -0,0 +1,112 @@
+package excel
+
+import (
+	"strings"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+)
+
+/**
+Workbook build a workbook whose sheets have different struct types, ex:
+
+	NewWorkbook().AddSheet("订单", orders).AddSheet("明细", items).Build()
+*/
+type Workbook struct {
+	options []Option
+	sheets  []*workbookSheet
+}
+
+type workbookSheet struct {
+	name    string
+	rows    []interface{}
+	options []Option
+}
+
+/**
+NewWorkbook return a workbook builder, the options are applied to every sheet
+*/
+func NewWorkbook(options ...Option) *Workbook {
+	return &Workbook{options: options}
+}
+
+/**
+AddSheet add a sheet of rows, the header is parsed from the type of rows, and the options are only applied
+to this sheet, such as HeaderStyle, Footer and GroupBy. The sheet splitting options are ignored.
+*/
+func (w *Workbook) AddSheet(name string, rows []interface{}, options ...Option) *Workbook {
+	w.sheets = append(w.sheets, &workbookSheet{name: name, rows: rows, options: options})
+	return w
+}
+
+/**
+Build write the sheets to a new excel
+*/
+func (w *Workbook) Build() (e *Excel, err error) {
+	if len(w.sheets) == 0 {
+		err = errors.New("no sheet is added")
+		return
+	}
+
+	e = newExcel()
+	for _, option := range w.options {
+		option(e)
+	}
+	e.ex = excelize.NewFile()
+
+	// the sheet names are case insensitive
+	names := make(map[string]bool, len(w.sheets))
+	for _, sheet := range w.sheets {
+		if sheet.name = sanitizeSheetName(sheet.name); sheet.name == "" || names[strings.ToLower(sheet.name)] {
+			err = errors.Errorf("sheet name %s is empty or duplicated", sheet.name)
+			return
+		}
+		names[strings.ToLower(sheet.name)] = true
+		e.ex.NewSheet(sheet.name)
+	}
+	if !names[strings.ToLower(_defaultSheetName)] {
+		// delete default sheet
+		e.ex.DeleteSheet(_defaultSheetName)
+	}
+
+	for _, sheet := range w.sheets {
+		if err = w.writeSheet(e.ex, sheet); err != nil {
+			err = errors.Wrapf(err, "w.writeSheet %s", sheet.name)
+			return
+		}
+	}
+
+	if err = e.postInitialize(nil, nil); err != nil {
+		err = errors.Wrapf(err, "s.postInitialize")
+		return
+	}
+
+	return
+}
+
+/**
+writeSheet write the header and rows of sheet by the workbook and sheet options
+*/
+func (w *Workbook) writeSheet(ex *excelize.File, sheet *workbookSheet) (err error) {
+	se := newExcel()
+	for _, option := range w.options {
+		option(se)
+	}
+	for _, option := range sheet.options {
+		option(se)
+	}
+	se.ex = ex
+	se.sheetParts = []*sheetPart{{name: sheet.name, rows: sheet.rows}}
+
+	if err = se.initStyle(); err != nil {
+		err = errors.Wrap(err, "se.initStyle")
+		return
+	}
+	beforeExport(sheet.rows)
+	if err = se.initFromData(sheet.rows); err != nil {
+		err = errors.Wrap(err, "se.initFromData")
+		return
+	}
+
+	return
+}
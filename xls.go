@@ -0,0 +1,605 @@
+package excel
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/360EntSecGroup-Skylar/excelize/v2"
+	"github.com/pkg/errors"
+	"github.com/richardlehane/mscfb"
+)
+
+// BIFF8 record types
+const (
+	_xlsFormula    = 0x0006
+	_xlsEOF        = 0x000A
+	_xlsDateMode   = 0x0022
+	_xlsFilePass   = 0x002F
+	_xlsContinue   = 0x003C
+	_xlsBoundSheet = 0x0085
+	_xlsMulRK      = 0x00BD
+	_xlsRString    = 0x00D6
+	_xlsXF         = 0x00E0
+	_xlsMergeCells = 0x00E5
+	_xlsSST        = 0x00FC
+	_xlsLabelSST   = 0x00FD
+	_xlsNumber     = 0x0203
+	_xlsLabel      = 0x0204
+	_xlsBoolErr    = 0x0205
+	_xlsString     = 0x0207
+	_xlsRK         = 0x027E
+	_xlsFormat     = 0x041E
+	_xlsBOF        = 0x0809
+
+	_xlsBIFF8     = 0x0600
+	_xlsWorksheet = 0x00
+)
+
+type xlsRecord struct {
+	typ  uint16
+	data []byte
+}
+
+type xlsSheet struct {
+	loadedSheet
+	offset int
+}
+
+type xlsWorkbook struct {
+	stream   []byte
+	sst      []string
+	xfs      []uint16
+	formats  map[uint16]string
+	date1904 bool
+	sheets   []*xlsSheet
+}
+
+/**
+NewExcelFromXLS read a legacy BIFF8 .xls workbook, the cells and the merged cells are loaded to a xlsx
+workbook in memory, so the header is built and the rows are scanned the same as a xlsx file.
+The cell values are read as displayed text, and the dates are formatted as "2006-01-02".
+*/
+func NewExcelFromXLS(reader io.Reader, options ...Option) (e *Excel, err error) {
+	e = newExcel()
+	for _, option := range options {
+		option(e)
+	}
+
+	p := e.progress.start(PhaseOpen, "", 0)
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		err = errors.Wrap(err, "ioutil.ReadAll")
+		return
+	}
+	wb, err := readXLSWorkbook(data)
+	if err != nil {
+		err = errors.Wrap(err, "readXLSWorkbook")
+		return
+	}
+
+	e.ex = excelize.NewFile()
+	sheets := make([]*loadedSheet, 0, len(wb.sheets))
+	for _, sheet := range wb.sheets {
+		sheets = append(sheets, &sheet.loadedSheet)
+	}
+	if err = loadSheets(e.ex, sheets); err != nil {
+		err = errors.Wrap(err, "loadSheets")
+		return
+	}
+
+	if err = e.postInitialize(nil, nil); err != nil {
+		err = errors.Wrapf(err, "s.postInitialize")
+		return
+	}
+	p.finish()
+
+	return
+}
+
+/**
+readXLSWorkbook read the Workbook stream in the compound file, and parse the globals and the worksheets
+*/
+func readXLSWorkbook(data []byte) (wb *xlsWorkbook, err error) {
+	doc, err := mscfb.New(bytes.NewReader(data))
+	if err != nil {
+		err = errors.Wrap(err, "mscfb.New")
+		return
+	}
+
+	wb = &xlsWorkbook{formats: make(map[uint16]string)}
+	for entry, e := doc.Next(); e == nil; entry, e = doc.Next() {
+		switch entry.Name {
+		case "Workbook":
+			if wb.stream, err = ioutil.ReadAll(entry); err != nil {
+				err = errors.Wrap(err, "ioutil.ReadAll")
+				return
+			}
+		case "Book":
+			err = errors.New("BIFF5 workbook is not supported")
+			return
+		}
+	}
+	if wb.stream == nil {
+		err = errors.New("workbook stream is not found, it's not a xls file")
+		return
+	}
+
+	if err = wb.readGlobals(); err != nil {
+		err = errors.Wrap(err, "wb.readGlobals")
+		return
+	}
+	for _, sheet := range wb.sheets {
+		if err = wb.readSheet(sheet); err != nil {
+			err = errors.Wrapf(err, "wb.readSheet %s", sheet.name)
+			return
+		}
+	}
+
+	return
+}
+
+/**
+readRecord return the record at pos and the position of the next record
+*/
+func (wb *xlsWorkbook) readRecord(pos int) (rec xlsRecord, next int, err error) {
+	if pos+4 > len(wb.stream) {
+		err = io.ErrUnexpectedEOF
+		return
+	}
+	rec.typ = binary.LittleEndian.Uint16(wb.stream[pos:])
+	size := int(binary.LittleEndian.Uint16(wb.stream[pos+2:]))
+	next = pos + 4 + size
+	if next > len(wb.stream) {
+		err = io.ErrUnexpectedEOF
+		return
+	}
+	rec.data = wb.stream[pos+4 : next]
+	return
+}
+
+func (wb *xlsWorkbook) readGlobals() (err error) {
+	rec, pos, err := wb.readRecord(0)
+	if err != nil {
+		return
+	}
+	if rec.typ != _xlsBOF || len(rec.data) < 2 || binary.LittleEndian.Uint16(rec.data) != _xlsBIFF8 {
+		err = errors.New("only BIFF8 workbook is supported")
+		return
+	}
+
+	for rec.typ != _xlsEOF {
+		if rec, pos, err = wb.readRecord(pos); err != nil {
+			return
+		}
+
+		switch rec.typ {
+		case _xlsFilePass:
+			err = errors.New("encrypted xls is not supported")
+			return
+		case _xlsDateMode:
+			wb.date1904 = len(rec.data) >= 2 && binary.LittleEndian.Uint16(rec.data) == 1
+		case _xlsFormat:
+			if len(rec.data) > 2 {
+				s, _ := newXLSStringReader([][]byte{rec.data[2:]}).readString(false)
+				wb.formats[binary.LittleEndian.Uint16(rec.data)] = s
+			}
+		case _xlsXF:
+			if len(rec.data) >= 4 {
+				wb.xfs = append(wb.xfs, binary.LittleEndian.Uint16(rec.data[2:]))
+			}
+		case _xlsBoundSheet:
+			if len(rec.data) < 8 || rec.data[5] != _xlsWorksheet {
+				continue
+			}
+			name, _ := newXLSStringReader([][]byte{rec.data[6:]}).readShortString()
+			wb.sheets = append(wb.sheets, &xlsSheet{loadedSheet: loadedSheet{name: name}, offset: int(binary.LittleEndian.Uint32(rec.data))})
+		case _xlsSST:
+			// the shared strings continue in the following CONTINUE records
+			segments := [][]byte{rec.data}
+			for {
+				next, nextPos, e := wb.readRecord(pos)
+				if e != nil || next.typ != _xlsContinue {
+					break
+				}
+				segments = append(segments, next.data)
+				pos = nextPos
+			}
+			if wb.sst, err = readXLSSST(segments); err != nil {
+				err = errors.Wrap(err, "readXLSSST")
+				return
+			}
+		}
+	}
+
+	return
+}
+
+func readXLSSST(segments [][]byte) (sst []string, err error) {
+	r := newXLSStringReader(segments)
+	if _, err = r.readUint32(); err != nil {
+		return
+	}
+	count, err := r.readUint32()
+	if err != nil {
+		return
+	}
+
+	sst = make([]string, 0, count)
+	for i := uint32(0); i < count; i++ {
+		var s string
+		if s, err = r.readString(true); err != nil {
+			return
+		}
+		sst = append(sst, s)
+	}
+	return
+}
+
+func (wb *xlsWorkbook) readSheet(sheet *xlsSheet) (err error) {
+	var (
+		rec   xlsRecord
+		pos   = sheet.offset
+		depth int
+		// the cell of the last formula whose string value is in the next STRING record
+		formulaCell *loadedCell
+	)
+	for {
+		if rec, pos, err = wb.readRecord(pos); err != nil {
+			return
+		}
+
+		switch rec.typ {
+		case _xlsBOF:
+			depth++
+			continue
+		case _xlsEOF:
+			if depth--; depth <= 0 {
+				return
+			}
+			continue
+		}
+		// skip the embedded substreams, such as charts
+		if depth != 1 {
+			continue
+		}
+
+		data := rec.data
+		if rec.typ != _xlsMergeCells && rec.typ != _xlsString && len(data) < 6 {
+			continue
+		}
+		cell := loadedCell{}
+		if len(data) >= 4 {
+			cell.row, cell.col = int(binary.LittleEndian.Uint16(data)), int(binary.LittleEndian.Uint16(data[2:]))
+		}
+
+		switch rec.typ {
+		case _xlsLabelSST:
+			if len(data) < 10 {
+				continue
+			}
+			if i := int(binary.LittleEndian.Uint32(data[6:])); i < len(wb.sst) {
+				cell.value = wb.sst[i]
+			}
+		case _xlsLabel, _xlsRString:
+			if cell.value, err = newXLSStringReader([][]byte{data[6:]}).readString(false); err != nil {
+				return
+			}
+		case _xlsNumber:
+			if len(data) < 14 {
+				continue
+			}
+			cell.value = wb.formatNumber(math.Float64frombits(binary.LittleEndian.Uint64(data[6:])), binary.LittleEndian.Uint16(data[4:]))
+		case _xlsRK:
+			if len(data) < 10 {
+				continue
+			}
+			cell.value = wb.formatNumber(decodeRK(binary.LittleEndian.Uint32(data[6:])), binary.LittleEndian.Uint16(data[4:]))
+		case _xlsMulRK:
+			// ixfe and rk of every cell, and the last col at the end
+			for i := 4; i+6 <= len(data)-2; i += 6 {
+				value := wb.formatNumber(decodeRK(binary.LittleEndian.Uint32(data[i+2:])), binary.LittleEndian.Uint16(data[i:]))
+				sheet.cells = append(sheet.cells, loadedCell{row: cell.row, col: cell.col + (i-4)/6, value: value})
+			}
+			continue
+		case _xlsBoolErr:
+			if len(data) < 8 {
+				continue
+			}
+			cell.value = formatXLSBoolErr(data[6], data[7])
+		case _xlsFormula:
+			if len(data) < 14 {
+				continue
+			}
+			if binary.LittleEndian.Uint16(data[12:]) != 0xFFFF {
+				cell.value = wb.formatNumber(math.Float64frombits(binary.LittleEndian.Uint64(data[6:])), binary.LittleEndian.Uint16(data[4:]))
+				break
+			}
+			switch data[6] {
+			case 0:
+				sheet.cells = append(sheet.cells, cell)
+				formulaCell = &sheet.cells[len(sheet.cells)-1]
+				continue
+			case 1, 2:
+				cell.value = formatXLSBoolErr(data[8], data[6]-1)
+			default:
+				continue
+			}
+		case _xlsString:
+			if formulaCell != nil {
+				if formulaCell.value, err = newXLSStringReader([][]byte{data}).readString(false); err != nil {
+					return
+				}
+				formulaCell = nil
+			}
+			continue
+		case _xlsMergeCells:
+			if len(data) < 2 {
+				continue
+			}
+			count := int(binary.LittleEndian.Uint16(data))
+			for i := 0; i < count && 2+i*8+8 <= len(data); i++ {
+				ref := data[2+i*8:]
+				sheet.merges = append(sheet.merges, loadedMerge{
+					rowStart: int(binary.LittleEndian.Uint16(ref)), rowEnd: int(binary.LittleEndian.Uint16(ref[2:])),
+					colStart: int(binary.LittleEndian.Uint16(ref[4:])), colEnd: int(binary.LittleEndian.Uint16(ref[6:])),
+				})
+			}
+			continue
+		default:
+			continue
+		}
+
+		sheet.cells = append(sheet.cells, cell)
+	}
+}
+
+/**
+formatNumber format the number by the number format of the XF, only the dates are formatted,
+the other numbers are formatted as the shortest decimal
+*/
+func (wb *xlsWorkbook) formatNumber(v float64, xf uint16) string {
+	if int(xf) < len(wb.xfs) && wb.isDateFormat(wb.xfs[xf]) {
+		t := xlsTime(v, wb.date1904)
+		switch {
+		case v < 1:
+			return t.Format("15:04:05")
+		case t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0:
+			return t.Format(_dateLayout)
+		default:
+			return t.Format(_dateLayout + " 15:04:05")
+		}
+	}
+
+	return formatFloat(v)
+}
+
+var _xlsFormatLiteralRegexp = regexp.MustCompile(`"[^"]*"|\\.|\[[^\]]*\]`)
+
+func (wb *xlsWorkbook) isDateFormat(ifmt uint16) bool {
+	switch {
+	case ifmt >= 14 && ifmt <= 22, ifmt >= 27 && ifmt <= 36, ifmt >= 45 && ifmt <= 47, ifmt >= 50 && ifmt <= 58:
+		return true
+	}
+
+	format, ok := wb.formats[ifmt]
+	if !ok {
+		return false
+	}
+	format = strings.ToLower(_xlsFormatLiteralRegexp.ReplaceAllString(format, ""))
+	return format != "general" && strings.ContainsAny(format, "ymdhs")
+}
+
+/**
+xlsTime convert the serial date number of excel to time
+*/
+func xlsTime(v float64, date1904 bool) time.Time {
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	} else if v < 61 {
+		// excel treats 1900 as a leap year
+		base = time.Date(1899, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	days := math.Floor(v)
+	seconds := math.Round((v - days) * 86400)
+	return base.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+}
+
+func decodeRK(rk uint32) (v float64) {
+	if rk&0x02 != 0 {
+		v = float64(int32(rk) >> 2)
+	} else {
+		v = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		v /= 100
+	}
+	return
+}
+
+func formatXLSBoolErr(value, isErr byte) string {
+	if isErr != 0 {
+		switch value {
+		case 0x00:
+			return "#NULL!"
+		case 0x07:
+			return "#DIV/0!"
+		case 0x0F:
+			return "#VALUE!"
+		case 0x17:
+			return "#REF!"
+		case 0x1D:
+			return "#NAME?"
+		case 0x24:
+			return "#NUM!"
+		default:
+			return "#N/A"
+		}
+	}
+	if value != 0 {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+/**
+xlsStringReader read the strings which may be split to several CONTINUE records,
+the character array continued in a new record begins with an option byte
+*/
+type xlsStringReader struct {
+	segments [][]byte
+	seg, pos int
+}
+
+func newXLSStringReader(segments [][]byte) *xlsStringReader {
+	return &xlsStringReader{segments: segments}
+}
+
+/**
+atBoundary move to the next segment if the current one is finished, and report whether it moved
+*/
+func (r *xlsStringReader) atBoundary() (moved bool, err error) {
+	for r.seg < len(r.segments) && r.pos >= len(r.segments[r.seg]) {
+		r.seg++
+		r.pos = 0
+		moved = true
+	}
+	if r.seg >= len(r.segments) {
+		err = io.ErrUnexpectedEOF
+	}
+	return
+}
+
+func (r *xlsStringReader) readByte() (b byte, err error) {
+	if _, err = r.atBoundary(); err != nil {
+		return
+	}
+	b = r.segments[r.seg][r.pos]
+	r.pos++
+	return
+}
+
+func (r *xlsStringReader) readUint16() (uint16, error) {
+	lo, err := r.readByte()
+	if err != nil {
+		return 0, err
+	}
+	hi, err := r.readByte()
+	if err != nil {
+		return 0, err
+	}
+	return uint16(lo) | uint16(hi)<<8, nil
+}
+
+func (r *xlsStringReader) readUint32() (uint32, error) {
+	lo, err := r.readUint16()
+	if err != nil {
+		return 0, err
+	}
+	hi, err := r.readUint16()
+	if err != nil {
+		return 0, err
+	}
+	return uint32(lo) | uint32(hi)<<16, nil
+}
+
+func (r *xlsStringReader) skip(n int) (err error) {
+	for i := 0; i < n; i++ {
+		if _, err = r.readByte(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+/**
+readString read a XLUnicodeString, or a XLUnicodeRichExtendedString if rich is true
+*/
+func (r *xlsStringReader) readString(rich bool) (s string, err error) {
+	cch, err := r.readUint16()
+	if err != nil {
+		return
+	}
+	return r.readChars(int(cch), rich)
+}
+
+/**
+readShortString read a ShortXLUnicodeString whose length is a byte
+*/
+func (r *xlsStringReader) readShortString() (s string, err error) {
+	cch, err := r.readByte()
+	if err != nil {
+		return
+	}
+	return r.readChars(int(cch), false)
+}
+
+func (r *xlsStringReader) readChars(cch int, rich bool) (s string, err error) {
+	flags, err := r.readByte()
+	if err != nil {
+		return
+	}
+
+	var runs, ext int
+	if rich && flags&0x08 != 0 {
+		var n uint16
+		if n, err = r.readUint16(); err != nil {
+			return
+		}
+		runs = int(n)
+	}
+	if rich && flags&0x04 != 0 {
+		var n uint32
+		if n, err = r.readUint32(); err != nil {
+			return
+		}
+		ext = int(n)
+	}
+
+	units := make([]uint16, 0, cch)
+	highByte := flags&0x01 != 0
+	for len(units) < cch {
+		var moved bool
+		if moved, err = r.atBoundary(); err != nil {
+			return
+		}
+		if moved {
+			// the option byte of the continued character array
+			var b byte
+			if b, err = r.readByte(); err != nil {
+				return
+			}
+			highByte = b&0x01 != 0
+		}
+
+		if highByte {
+			var u uint16
+			if u, err = r.readUint16(); err != nil {
+				return
+			}
+			units = append(units, u)
+		} else {
+			var b byte
+			if b, err = r.readByte(); err != nil {
+				return
+			}
+			units = append(units, uint16(b))
+		}
+	}
+
+	// the formatting runs and the phonetic data are ignored
+	if err = r.skip(runs*4 + ext); err != nil {
+		return
+	}
+
+	s = string(utf16.Decode(units))
+	return
+}